@@ -0,0 +1,266 @@
+// Package bedrock reads Bedrock Edition worlds. Chunks, entities and players are stored in the LevelDB database in
+// the db directory of a world, level.dat holds little endian NBT behind a nbt.BedrockHeader.
+package bedrock
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nitwhiz/go-nbt/leveldb"
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+const (
+	localPlayerKey     = "~local_player"
+	playerServerPrefix = "player_server_"
+	digestPrefix       = "digp"
+	actorPrefix        = "actorprefix"
+)
+
+// World is a Bedrock Edition world directory.
+type World struct {
+	dir string
+	db  *leveldb.DB
+}
+
+// Record is a chunk record of the database.
+type Record struct {
+	Key   Key
+	Value []byte
+}
+
+// ChunkPos is the position of a chunk in chunk coordinates.
+type ChunkPos struct {
+	X int
+	Z int
+}
+
+// Open opens the world directory dir, which has to contain a level.dat and a db directory. The World has to be
+// closed after use.
+func Open(dir string) (w *World, err error) {
+	if _, err = os.Stat(filepath.Join(dir, "level.dat")); err != nil {
+		return nil, fmt.Errorf("bedrock: %s is not a world: %w", dir, err)
+	}
+
+	db, err := leveldb.Open(filepath.Join(dir, "db"))
+
+	if err != nil {
+		return nil, fmt.Errorf("bedrock: opening database: %w", err)
+	}
+
+	return &World{dir: dir, db: db}, nil
+}
+
+// Close closes the database.
+func (w *World) Close() error {
+	return w.db.Close()
+}
+
+// Dir returns the world directory.
+func (w *World) Dir() string {
+	return w.dir
+}
+
+// DB returns the database of the world, for records not covered by World.
+func (w *World) DB() *leveldb.DB {
+	return w.db
+}
+
+// LevelTag returns the root tag of level.dat.
+func (w *World) LevelTag() (tag *nbt.Tag, err error) {
+	f, err := os.Open(filepath.Join(w.dir, "level.dat"))
+
+	if err != nil {
+		return
+	}
+
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	d := nbt.NewDecoder(f)
+
+	d.SetDialect(nbt.DialectBedrock)
+	d.SetBedrockHeader(true)
+
+	tag = &nbt.Tag{}
+
+	if err = d.Decode(tag); err != nil {
+		return nil, fmt.Errorf("bedrock: decoding level.dat: %w", err)
+	}
+
+	return
+}
+
+// DecodeTags decodes the concatenated little endian root tags of a record value.
+func DecodeTags(value []byte) (tags []*nbt.Tag, err error) {
+	d := nbt.NewDecoder(bytes.NewReader(value))
+
+	d.SetDialect(nbt.DialectBedrock)
+
+	for {
+		tag := &nbt.Tag{}
+
+		if err = d.Decode(tag); errors.Is(err, io.EOF) {
+			return tags, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("bedrock: decoding record: %w", err)
+		}
+
+		tags = append(tags, tag)
+	}
+}
+
+// decodeTag decodes a record value holding a single root tag.
+func decodeTag(value []byte) (tag *nbt.Tag, err error) {
+	tags, err := DecodeTags(value)
+
+	if err != nil {
+		return
+	}
+
+	if len(tags) != 1 {
+		return nil, fmt.Errorf("bedrock: expected a single tag, got %d", len(tags))
+	}
+
+	return tags[0], nil
+}
+
+// Records iterates over all chunk records of the database in key order. The iteration ends after the first error.
+func (w *World) Records() iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		for e, err := range w.db.Entries(nil) {
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+
+			k, ok := ParseKey(e.Key)
+
+			if !ok {
+				continue
+			}
+
+			if !yield(Record{Key: k, Value: e.Value}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Chunks returns the positions of all chunks of dimension d, identified by their version record.
+func (w *World) Chunks(d Dimension) (chunks []ChunkPos, err error) {
+	for r, err := range w.Records() {
+		if err != nil {
+			return nil, err
+		}
+
+		if r.Key.Dimension != d || (r.Key.Type != RecordVersion && r.Key.Type != RecordLegacyVersion) {
+			continue
+		}
+
+		chunks = append(chunks, ChunkPos{X: r.Key.X, Z: r.Key.Z})
+	}
+
+	return
+}
+
+// Record returns the value of the record k, or leveldb.ErrNotFound.
+func (w *World) Record(k Key) ([]byte, error) {
+	return w.db.Get(k.Bytes())
+}
+
+// BlockEntities returns the block entities of the chunk at x, z in dimension d.
+func (w *World) BlockEntities(d Dimension, x, z int) ([]*nbt.Tag, error) {
+	return w.recordTags(Key{X: x, Z: z, Dimension: d, Type: RecordBlockEntity})
+}
+
+func (w *World) recordTags(k Key) ([]*nbt.Tag, error) {
+	value, err := w.Record(k)
+
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return DecodeTags(value)
+}
+
+// Entities returns the entities of the chunk at x, z in dimension d. Since 1.18.30 entities are stored as separate
+// records listed by a digest of the chunk, older worlds store them in a RecordEntity record of the chunk.
+func (w *World) Entities(d Dimension, x, z int) (tags []*nbt.Tag, err error) {
+	if tags, err = w.recordTags(Key{X: x, Z: z, Dimension: d, Type: RecordEntity}); err != nil {
+		return
+	}
+
+	digest, err := w.db.Get(append([]byte(digestPrefix), chunkPrefix(d, x, z)...))
+
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return tags, nil
+	} else if err != nil {
+		return
+	}
+
+	if len(digest)%8 != 0 {
+		return nil, fmt.Errorf("bedrock: invalid entity digest length %d", len(digest))
+	}
+
+	for id := range slices.Chunk(digest, 8) {
+		var value []byte
+
+		if value, err = w.db.Get(append([]byte(actorPrefix), id...)); err != nil {
+			return nil, fmt.Errorf("bedrock: reading entity %x: %w", id, err)
+		}
+
+		var tag *nbt.Tag
+
+		if tag, err = decodeTag(value); err != nil {
+			return
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return
+}
+
+// LocalPlayer returns the player of a single player world.
+func (w *World) LocalPlayer() (*nbt.Tag, error) {
+	return w.tag([]byte(localPlayerKey))
+}
+
+// Players returns the ids of all players besides the local one, in key order.
+func (w *World) Players() (ids []string, err error) {
+	for e, err := range w.db.Entries([]byte(playerServerPrefix)) {
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, strings.TrimPrefix(string(e.Key), playerServerPrefix))
+	}
+
+	return
+}
+
+// Player returns the player with the given id, see Players.
+func (w *World) Player(id string) (*nbt.Tag, error) {
+	return w.tag([]byte(playerServerPrefix + id))
+}
+
+func (w *World) tag(key []byte) (tag *nbt.Tag, err error) {
+	value, err := w.db.Get(key)
+
+	if err != nil {
+		return
+	}
+
+	return decodeTag(value)
+}
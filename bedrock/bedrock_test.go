@@ -0,0 +1,165 @@
+package bedrock
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nitwhiz/go-nbt/leveldb"
+)
+
+// testWorld is written by testdata/bedrock-gen with goleveldb, independent of the leveldb package. Its tables hold
+// zlib and zlib-raw compressed blocks.
+const testWorld = "../testdata/bedrock"
+
+func openTestWorld(t *testing.T) *World {
+	t.Helper()
+
+	w, err := Open(testWorld)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = w.Close()
+	})
+
+	return w
+}
+
+func TestParseKey(t *testing.T) {
+	keys := []Key{
+		{X: 3, Z: -7, Type: RecordVersion},
+		{X: -100, Z: 2000, Dimension: End, Type: RecordBlockEntity},
+		{X: 1, Z: 1, Type: RecordSubChunkPrefix, SubChunk: -4},
+		{X: 0, Z: -1, Dimension: Nether, Type: RecordSubChunkPrefix, SubChunk: 7},
+	}
+
+	for _, k := range keys {
+		if res, ok := ParseKey(k.Bytes()); !ok || res != k {
+			t.Fatalf("expected %+v, got %+v", k, res)
+		}
+	}
+
+	for _, s := range []string{"~local_player", "BiomeData", "Overworld", "scoreboard", "player_server_x"} {
+		if k, ok := ParseKey([]byte(s)); ok {
+			t.Fatalf("expected %q not to be a chunk key, got %+v", s, k)
+		}
+	}
+}
+
+func TestLevelTag(t *testing.T) {
+	level, err := openTestWorld(t).LevelTag()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name, _ := level.Find("LevelName"); name.Value != "Bedrock Test" {
+		t.Fatalf("expected level name Bedrock Test, got %v", name.Value)
+	}
+}
+
+func TestChunks(t *testing.T) {
+	w := openTestWorld(t)
+
+	chunks, err := w.Chunks(Overworld)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(chunks, []ChunkPos{{X: 0, Z: 0}, {X: 5, Z: -3}}) {
+		t.Fatalf("expected overworld chunks 0,0 and 5,-3, got %v", chunks)
+	}
+
+	if chunks, err = w.Chunks(Nether); err != nil || !slices.Equal(chunks, []ChunkPos{{X: -1, Z: 2}}) {
+		t.Fatalf("expected nether chunk -1,2, got %v, %v", chunks, err)
+	}
+
+	if _, err = w.Record(Key{X: 0, Z: 0, Type: RecordSubChunkPrefix, SubChunk: -4}); !errors.Is(err, leveldb.ErrNotFound) {
+		t.Fatalf("expected deleted sub chunk, got %v", err)
+	}
+}
+
+func TestBlockEntities(t *testing.T) {
+	tags, err := openTestWorld(t).BlockEntities(Overworld, 0, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tags) != 2 {
+		t.Fatalf("expected 2 block entities, got %d", len(tags))
+	}
+
+	if id, _ := tags[1].Find("id"); id.Value != "Sign" {
+		t.Fatalf("expected Sign, got %v", id.Value)
+	}
+}
+
+func TestEntities(t *testing.T) {
+	w := openTestWorld(t)
+
+	tags, err := w.Entities(Overworld, 0, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+
+	for _, tag := range tags {
+		id, _ := tag.Find("identifier")
+		ids = append(ids, id.Value.(string))
+	}
+
+	if !slices.Equal(ids, []string{"minecraft:cow", "minecraft:zombie"}) {
+		t.Fatalf("expected cow and zombie, got %v", ids)
+	}
+
+	// the legacy entity record of the nether chunk
+	if tags, err = w.Entities(Nether, -1, 2); err != nil || len(tags) != 1 {
+		t.Fatalf("expected 1 nether entity, got %d, %v", len(tags), err)
+	}
+
+	if tags, err = w.Entities(End, 0, 0); err != nil || len(tags) != 0 {
+		t.Fatalf("expected no end entities, got %d, %v", len(tags), err)
+	}
+}
+
+func TestPlayers(t *testing.T) {
+	w := openTestWorld(t)
+
+	local, err := w.LocalPlayer()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the log holds a newer version than the tables
+	if level, _ := local.Find("PlayerLevel"); level == nil || level.Value != int32(30) {
+		t.Fatalf("expected local player level 30, got %v", level)
+	}
+
+	ids, err := w.Players()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(ids, []string{"8f1b3c6e-2a4d-4c7e-9b0a-1d2e3f405162"}) {
+		t.Fatalf("unexpected players %v", ids)
+	}
+
+	p, err := w.Player(ids[0])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if level, _ := p.Find("PlayerLevel"); level.Value != int32(7) {
+		t.Fatalf("expected player level 7, got %v", level.Value)
+	}
+}
@@ -0,0 +1,121 @@
+package bedrock
+
+import (
+	"encoding/binary"
+)
+
+// Dimension is the numeric id of a dimension, as used in chunk keys.
+type Dimension int32
+
+const (
+	Overworld Dimension = 0
+	Nether    Dimension = 1
+	End       Dimension = 2
+)
+
+// RecordType is the tag byte of a chunk key, telling what the record holds.
+type RecordType byte
+
+const (
+	RecordData3D                             RecordType = 0x2b
+	RecordVersion                            RecordType = 0x2c
+	RecordData2D                             RecordType = 0x2d
+	RecordData2DLegacy                       RecordType = 0x2e
+	RecordSubChunkPrefix                     RecordType = 0x2f
+	RecordLegacyTerrain                      RecordType = 0x30
+	RecordBlockEntity                        RecordType = 0x31
+	RecordEntity                             RecordType = 0x32
+	RecordPendingTicks                       RecordType = 0x33
+	RecordLegacyBlockExtraData               RecordType = 0x34
+	RecordBiomeState                         RecordType = 0x35
+	RecordFinalizedState                     RecordType = 0x36
+	RecordConversionData                     RecordType = 0x37
+	RecordBorderBlocks                       RecordType = 0x38
+	RecordHardcodedSpawners                  RecordType = 0x39
+	RecordRandomTicks                        RecordType = 0x3a
+	RecordChecksums                          RecordType = 0x3b
+	RecordGenerationSeed                     RecordType = 0x3c
+	RecordGeneratedPreCavesAndCliffsBlending RecordType = 0x3d
+	RecordBlendingBiomeHeight                RecordType = 0x3e
+	RecordMetaDataHash                       RecordType = 0x3f
+	RecordBlendingData                       RecordType = 0x40
+	RecordActorDigestVersion                 RecordType = 0x41
+	RecordLegacyVersion                      RecordType = 0x76
+)
+
+func (t RecordType) known() bool {
+	return (t >= RecordData3D && t <= RecordActorDigestVersion) || t == RecordLegacyVersion
+}
+
+// Key is a decoded chunk key. X and Z are chunk coordinates, SubChunk is the vertical index of a
+// RecordSubChunkPrefix record.
+type Key struct {
+	X         int
+	Z         int
+	Dimension Dimension
+	Type      RecordType
+	SubChunk  int8
+}
+
+// chunkPrefix returns the part of a chunk key identifying the chunk. The dimension is left out for the Overworld.
+func chunkPrefix(d Dimension, x, z int) (bs []byte) {
+	bs = binary.LittleEndian.AppendUint32(bs, uint32(int32(x)))
+	bs = binary.LittleEndian.AppendUint32(bs, uint32(int32(z)))
+
+	if d != Overworld {
+		bs = binary.LittleEndian.AppendUint32(bs, uint32(d))
+	}
+
+	return
+}
+
+// Bytes returns the database key of k.
+func (k Key) Bytes() []byte {
+	bs := append(chunkPrefix(k.Dimension, k.X, k.Z), byte(k.Type))
+
+	if k.Type == RecordSubChunkPrefix {
+		bs = append(bs, byte(k.SubChunk))
+	}
+
+	return bs
+}
+
+// ParseKey decodes a chunk key. ok is false for keys not belonging to a chunk, like "~local_player".
+func ParseKey(bs []byte) (k Key, ok bool) {
+	var subChunk bool
+
+	switch len(bs) {
+	case 9, 13:
+	case 10, 14:
+		subChunk = true
+	default:
+		return
+	}
+
+	k.X = int(int32(binary.LittleEndian.Uint32(bs[0:4])))
+	k.Z = int(int32(binary.LittleEndian.Uint32(bs[4:8])))
+
+	rest := bs[8:]
+
+	if len(rest) > 2 {
+		k.Dimension = Dimension(binary.LittleEndian.Uint32(rest[0:4]))
+		rest = rest[4:]
+
+		// the Overworld is never stored with its id
+		if k.Dimension == Overworld {
+			return
+		}
+	}
+
+	k.Type = RecordType(rest[0])
+
+	if !k.Type.known() || subChunk != (k.Type == RecordSubChunkPrefix) {
+		return
+	}
+
+	if subChunk {
+		k.SubChunk = int8(rest[1])
+	}
+
+	return k, true
+}
@@ -0,0 +1,144 @@
+package block
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+// Air is the state of an empty block.
+var Air = State{Name: "minecraft:air"}
+
+// State is a block state as stored in palettes: a namespaced block name and its properties.
+type State struct {
+	Name       string
+	Properties map[string]string
+}
+
+// String returns the state in the notation used by commands, e.g. minecraft:oak_log[axis=y].
+func (s State) String() string {
+	if len(s.Properties) == 0 {
+		return s.Name
+	}
+
+	sb := strings.Builder{}
+
+	sb.WriteString(s.Name)
+	sb.WriteByte('[')
+
+	for i, key := range slices.Sorted(maps.Keys(s.Properties)) {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+
+		sb.WriteString(key + "=" + s.Properties[key])
+	}
+
+	sb.WriteByte(']')
+
+	return sb.String()
+}
+
+// Equal reports whether s and o have the same name and properties.
+func (s State) Equal(o State) bool {
+	return s.Name == o.Name && maps.Equal(s.Properties, o.Properties)
+}
+
+// ParseState parses the notation returned by State.String.
+func ParseState(str string) (s State, err error) {
+	name, props, found := strings.Cut(str, "[")
+
+	s.Name = name
+
+	if !found {
+		return
+	}
+
+	if !strings.HasSuffix(props, "]") {
+		return State{}, fmt.Errorf("block: missing ] in %q", str)
+	}
+
+	props = strings.TrimSuffix(props, "]")
+
+	if props == "" {
+		return
+	}
+
+	s.Properties = map[string]string{}
+
+	for _, prop := range strings.Split(props, ",") {
+		key, value, found := strings.Cut(prop, "=")
+
+		if !found {
+			return State{}, fmt.Errorf("block: invalid property %q in %q", prop, str)
+		}
+
+		s.Properties[key] = value
+	}
+
+	return
+}
+
+// StateFromTag reads a palette entry, a compound with a Name string and an optional Properties compound.
+func StateFromTag(tag *nbt.Tag) (s State, err error) {
+	if tag.Type != nbt.TypeCompound {
+		return State{}, errors.New("block: palette entry is not a compound")
+	}
+
+	name, ok := tag.Find("Name")
+
+	if !ok || name.Type != nbt.TypeString {
+		return State{}, errors.New("block: palette entry has no Name")
+	}
+
+	s.Name = name.Value.(string)
+
+	props, ok := tag.Find("Properties")
+
+	if !ok {
+		return
+	}
+
+	c, ok := props.Value.(nbt.Compound)
+
+	if !ok {
+		return State{}, fmt.Errorf("block: properties of %s are not a compound", s.Name)
+	}
+
+	s.Properties = make(map[string]string, len(c))
+
+	for key, value := range c {
+		str, ok := value.Value.(string)
+
+		if !ok {
+			return State{}, fmt.Errorf("block: property %s of %s is not a string", key, s.Name)
+		}
+
+		s.Properties[key] = str
+	}
+
+	return
+}
+
+// Tag returns s as palette entry. Properties are left out if there are none.
+func (s State) Tag() *nbt.Tag {
+	c := nbt.Compound{
+		"Name": {Type: nbt.TypeString, Name: []byte("Name"), Value: s.Name},
+	}
+
+	if len(s.Properties) > 0 {
+		props := make(nbt.Compound, len(s.Properties))
+
+		for key, value := range s.Properties {
+			props[key] = &nbt.Tag{Type: nbt.TypeString, Name: []byte(key), Value: value}
+		}
+
+		c["Properties"] = &nbt.Tag{Type: nbt.TypeCompound, Name: []byte("Properties"), Value: props}
+	}
+
+	return &nbt.Tag{Type: nbt.TypeCompound, Value: c}
+}
@@ -0,0 +1,43 @@
+package block
+
+import (
+	"testing"
+)
+
+func TestParseState(t *testing.T) {
+	s, err := ParseState("minecraft:oak_stairs[half=top,facing=north]")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Name != "minecraft:oak_stairs" || s.Properties["facing"] != "north" || s.Properties["half"] != "top" {
+		t.Fatalf("unexpected state %v", s)
+	}
+
+	if s.String() != "minecraft:oak_stairs[facing=north,half=top]" {
+		t.Fatalf("expected sorted properties, got %s", s)
+	}
+
+	if _, err := ParseState("minecraft:stone[broken"); err == nil {
+		t.Fatalf("expected error for missing ]")
+	}
+}
+
+func TestStateTag(t *testing.T) {
+	s := State{Name: "minecraft:oak_log", Properties: map[string]string{"axis": "x"}}
+
+	res, err := StateFromTag(s.Tag())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !res.Equal(s) {
+		t.Fatalf("expected %s, got %s", s, res)
+	}
+
+	if _, ok := Air.Tag().Find("Properties"); ok {
+		t.Fatalf("expected no properties for air")
+	}
+}
@@ -0,0 +1,82 @@
+package chunk
+
+import (
+	"fmt"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+// BiomeVolume is the number of 4×4×4 biome cells in a section.
+const BiomeVolume = 4 * 4 * 4
+
+// Biomes holds the biome ids of a section in cells of 4×4×4 blocks, indexed by y<<4 | z<<2 | x.
+type Biomes [BiomeVolume]string
+
+// At returns the biome of the cell containing the section relative block position x, y, z.
+func (b *Biomes) At(x, y, z int) string {
+	return b[(y>>2)<<4|(z>>2)<<2|x>>2]
+}
+
+// Set replaces the biome of the cell containing the section relative block position x, y, z.
+func (b *Biomes) Set(x, y, z int, biome string) {
+	b[(y>>2)<<4|(z>>2)<<2|x>>2] = biome
+}
+
+func biomeFromTag(tag *nbt.Tag) (string, error) {
+	s, ok := tag.Value.(string)
+
+	if !ok {
+		return "", fmt.Errorf("chunk: biome palette entry is not a string")
+	}
+
+	return s, nil
+}
+
+func biomeTag(biome string) *nbt.Tag {
+	return &nbt.Tag{Type: nbt.TypeString, Value: biome}
+}
+
+// DecodeBiomes unpacks the palette indices in data and resolves them with palette.
+func DecodeBiomes(palette nbt.List, data []int64) (b *Biomes, err error) {
+	biomes, err := decodePalette(palette, data, BiomeVolume, 0, LayoutAligned, biomeFromTag)
+
+	if err != nil {
+		return
+	}
+
+	b = new(Biomes)
+
+	copy(b[:], biomes)
+
+	return
+}
+
+// Encode builds a palette of the distinct biomes in b and packs the indices with the fewest bits possible.
+func (b *Biomes) Encode() (palette nbt.List, data []int64) {
+	return encodePalette(b[:], 0, LayoutAligned, func(s string) string { return s }, biomeTag)
+}
+
+// Tag returns the biomes compound of b. The data array is left out if all cells have the same biome.
+func (b *Biomes) Tag() *nbt.Tag {
+	palette, data := b.Encode()
+
+	return paletteContainer("biomes", palette, data)
+}
+
+// ReadBiomes reads the biomes compound of a section, as stored since 1.18. It returns nil if the section has
+// no biomes. The numeric Biomes array of older chunks is not supported.
+func ReadBiomes(section *nbt.Tag) (*Biomes, error) {
+	c, ok := section.Find("biomes")
+
+	if !ok || c.Type != nbt.TypeCompound {
+		return nil, nil
+	}
+
+	palette, data, ok := readPaletteContainer(section, "biomes", "palette", "data")
+
+	if !ok {
+		return nil, nil
+	}
+
+	return DecodeBiomes(palette, data)
+}
@@ -0,0 +1,60 @@
+package chunk
+
+import (
+	"testing"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+func TestBiomesRoundTrip(t *testing.T) {
+	b := new(Biomes)
+
+	for i := range b {
+		b[i] = "minecraft:plains"
+	}
+
+	b.Set(15, 15, 15, "minecraft:river")
+	b.Set(0, 8, 0, "minecraft:forest")
+
+	palette, data := b.Encode()
+
+	if len(palette) != 3 || len(data) != PackedLength(BiomeVolume, 2, LayoutAligned) {
+		t.Fatalf("expected 3 entries with 2 bits, got %d entries and %d longs", len(palette), len(data))
+	}
+
+	res, err := ReadBiomes(&nbt.Tag{Type: nbt.TypeCompound, Value: nbt.Compound{"biomes": b.Tag()}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *res != *b {
+		t.Fatalf("biomes differ after round trip")
+	}
+
+	if biome := res.At(13, 12, 14); biome != "minecraft:river" {
+		t.Fatalf("expected river in the last cell, got %s", biome)
+	}
+
+	if biome := res.At(1, 9, 2); biome != "minecraft:forest" {
+		t.Fatalf("expected forest, got %s", biome)
+	}
+}
+
+func TestSingleBiome(t *testing.T) {
+	palette := nbt.List{{Type: nbt.TypeString, Value: "minecraft:the_void"}}
+
+	b, err := DecodeBiomes(palette, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b.At(0, 0, 0) != "minecraft:the_void" || b.At(15, 15, 15) != "minecraft:the_void" {
+		t.Fatalf("expected the_void everywhere")
+	}
+
+	if _, ok := b.Tag().Find("data"); ok {
+		t.Fatalf("expected no data for a single biome")
+	}
+}
@@ -0,0 +1,106 @@
+package chunk
+
+import (
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+const (
+	// SectionVolume is the number of blocks in a 16×16×16 section.
+	SectionVolume = 16 * 16 * 16
+	// minBlockBits is the minimum bits per block state index, smaller palettes are padded to it.
+	minBlockBits = 4
+)
+
+// BlockStates holds the blocks of a section, indexed by y<<8 | z<<4 | x.
+type BlockStates [SectionVolume]block.State
+
+// At returns the block at the section relative position x, y, z.
+func (b *BlockStates) At(x, y, z int) block.State {
+	return b[y<<8|z<<4|x]
+}
+
+// Set replaces the block at the section relative position x, y, z.
+func (b *BlockStates) Set(x, y, z int, s block.State) {
+	b[y<<8|z<<4|x] = s
+}
+
+// DecodeBlockStates unpacks the palette indices in data and resolves them with palette.
+func DecodeBlockStates(palette nbt.List, data []int64, layout Layout) (b *BlockStates, err error) {
+	states, err := decodePalette(palette, data, SectionVolume, minBlockBits, layout, block.StateFromTag)
+
+	if err != nil {
+		return
+	}
+
+	b = new(BlockStates)
+
+	copy(b[:], states)
+
+	return
+}
+
+// Encode builds a palette of the distinct states in b and packs the indices with the fewest bits possible, but
+// at least 4.
+func (b *BlockStates) Encode(layout Layout) (palette nbt.List, data []int64) {
+	return encodePalette(b[:], minBlockBits, layout, block.State.String, block.State.Tag)
+}
+
+// Tag returns the block_states compound of b as written since 1.18. The data array is left out if all blocks
+// are the same.
+func (b *BlockStates) Tag() *nbt.Tag {
+	palette, data := b.Encode(LayoutAligned)
+
+	return paletteContainer("block_states", palette, data)
+}
+
+// ReadBlockStates reads the blocks of a section, either from its block_states compound (1.18+) or from its
+// Palette and BlockStates entries (1.13 to 1.17). It returns nil if the section holds no blocks.
+func ReadBlockStates(section *nbt.Tag, dataVersion int32) (*BlockStates, error) {
+	palette, data, ok := readPaletteContainer(section, "block_states", "Palette", "BlockStates")
+
+	if !ok {
+		return nil, nil
+	}
+
+	return DecodeBlockStates(palette, data, LayoutFor(dataVersion))
+}
+
+// paletteContainer builds a compound with palette and data entries, data is left out for single entry palettes.
+func paletteContainer(name string, palette nbt.List, data []int64) *nbt.Tag {
+	c := nbt.Compound{
+		"palette": {Type: nbt.TypeList, Name: []byte("palette"), Value: palette},
+	}
+
+	if len(palette) > 1 {
+		c["data"] = &nbt.Tag{Type: nbt.TypeLongArray, Name: []byte("data"), Value: data}
+	}
+
+	return &nbt.Tag{Type: nbt.TypeCompound, Name: []byte(name), Value: c}
+}
+
+// readPaletteContainer finds the palette and data of a section, either in the container compound or, for older
+// versions, in the legacy entries of the section itself.
+func readPaletteContainer(section *nbt.Tag, container string, legacyPalette string, legacyData string) (palette nbt.List, data []int64, ok bool) {
+	paletteName, dataName := legacyPalette, legacyData
+
+	if c, found := section.Find(container); found && c.Type == nbt.TypeCompound {
+		section, paletteName, dataName = c, "palette", "data"
+	}
+
+	p, found := section.Find(paletteName)
+
+	if !found {
+		return
+	}
+
+	if palette, ok = p.Value.(nbt.List); !ok {
+		return
+	}
+
+	if d, found := section.Find(dataName); found {
+		data, _ = d.Value.([]int64)
+	}
+
+	return
+}
@@ -0,0 +1,125 @@
+package chunk
+
+import (
+	"testing"
+
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+var (
+	stone = block.State{Name: "minecraft:stone"}
+	log   = block.State{Name: "minecraft:oak_log", Properties: map[string]string{"axis": "y"}}
+)
+
+func testBlockStates() *BlockStates {
+	b := new(BlockStates)
+
+	for i := range b {
+		b[i] = block.Air
+	}
+
+	for x := range 16 {
+		for z := range 16 {
+			b.Set(x, 0, z, stone)
+		}
+	}
+
+	b.Set(3, 1, 5, log)
+
+	return b
+}
+
+func TestBlockStatesRoundTrip(t *testing.T) {
+	for _, layout := range []Layout{LayoutAligned, LayoutSpanning} {
+		b := testBlockStates()
+
+		palette, data := b.Encode(layout)
+
+		if len(palette) != 3 {
+			t.Fatalf("expected 3 palette entries, got %d", len(palette))
+		}
+
+		if len(data) != PackedLength(SectionVolume, 4, layout) {
+			t.Fatalf("expected 4 bits per block, got %d longs", len(data))
+		}
+
+		res, err := DecodeBlockStates(palette, data, layout)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := range b {
+			if !b[i].Equal(res[i]) {
+				t.Fatalf("block %d: expected %s, got %s", i, b[i], res[i])
+			}
+		}
+
+		if s := res.At(3, 1, 5); s.String() != "minecraft:oak_log[axis=y]" {
+			t.Fatalf("expected oak log, got %s", s)
+		}
+	}
+}
+
+func TestReadBlockStates(t *testing.T) {
+	b := testBlockStates()
+
+	section := &nbt.Tag{Type: nbt.TypeCompound, Value: nbt.Compound{"block_states": b.Tag()}}
+
+	res, err := ReadBlockStates(section, 3953)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !res.At(15, 0, 15).Equal(stone) {
+		t.Fatalf("expected stone, got %s", res.At(15, 0, 15))
+	}
+
+	// 1.15 sections store palette and indices directly, spanning longs
+	palette, data := b.Encode(LayoutSpanning)
+
+	legacy := &nbt.Tag{Type: nbt.TypeCompound, Value: nbt.Compound{
+		"Palette":     {Type: nbt.TypeList, Name: []byte("Palette"), Value: palette},
+		"BlockStates": {Type: nbt.TypeLongArray, Name: []byte("BlockStates"), Value: data},
+	}}
+
+	if res, err = ReadBlockStates(legacy, 2230); err != nil {
+		t.Fatal(err)
+	}
+
+	if !res.At(3, 1, 5).Equal(log) {
+		t.Fatalf("expected oak log, got %s", res.At(3, 1, 5))
+	}
+
+	empty := &nbt.Tag{Type: nbt.TypeCompound, Value: nbt.Compound{}}
+
+	if res, err = ReadBlockStates(empty, 3953); res != nil || err != nil {
+		t.Fatalf("expected no blocks, got %v, %v", res, err)
+	}
+}
+
+func TestSingleStateSection(t *testing.T) {
+	b := new(BlockStates)
+
+	for i := range b {
+		b[i] = block.Air
+	}
+
+	tag := b.Tag()
+
+	if _, ok := tag.Find("data"); ok {
+		t.Fatalf("expected no data for a single state")
+	}
+
+	res, err := ReadBlockStates(&nbt.Tag{Type: nbt.TypeCompound, Value: nbt.Compound{"block_states": tag}}, 3953)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !res.At(7, 7, 7).Equal(block.Air) {
+		t.Fatalf("expected air, got %s", res.At(7, 7, 7))
+	}
+}
@@ -0,0 +1,100 @@
+package chunk
+
+import (
+	"fmt"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+const (
+	HeightmapMotionBlocking         = "MOTION_BLOCKING"
+	HeightmapMotionBlockingNoLeaves = "MOTION_BLOCKING_NO_LEAVES"
+	HeightmapOceanFloor             = "OCEAN_FLOOR"
+	HeightmapWorldSurface           = "WORLD_SURFACE"
+)
+
+// Heightmap holds one height per block column of a chunk, indexed by z<<4 | x. Heights count blocks from the bottom
+// of the world, 0 means the column is empty.
+type Heightmap [16 * 16]int
+
+// At returns the height of the column at the chunk relative position x, z.
+func (h *Heightmap) At(x, z int) int {
+	return h[z<<4|x]
+}
+
+// Set replaces the height of the column at the chunk relative position x, z.
+func (h *Heightmap) Set(x, z int, height int) {
+	h[z<<4|x] = height
+}
+
+// heightmapBits returns the bits per column for a world worldHeight blocks high, 9 for both 256 and 384.
+func heightmapBits(worldHeight int) int {
+	return BitsFor(worldHeight+1, 1)
+}
+
+// DecodeHeightmap unpacks a heightmap long array of a world worldHeight blocks high.
+func DecodeHeightmap(data []int64, worldHeight int, layout Layout) (h *Heightmap, err error) {
+	heights, err := Unpack(data, len(h), heightmapBits(worldHeight), layout)
+
+	if err != nil {
+		return
+	}
+
+	h = new(Heightmap)
+
+	copy(h[:], heights)
+
+	return
+}
+
+// Encode packs h for a world worldHeight blocks high.
+func (h *Heightmap) Encode(worldHeight int, layout Layout) []int64 {
+	return Pack(h[:], heightmapBits(worldHeight), layout)
+}
+
+// ReadHeightmaps reads all heightmaps of a chunk, keyed by their names like HeightmapWorldSurface. chunk is the
+// root compound of the chunk, the Level compound of chunks before 1.18 is looked up automatically.
+func ReadHeightmaps(chunk *nbt.Tag, worldHeight int, dataVersion int32) (res map[string]*Heightmap, err error) {
+	if level, ok := chunk.Find("Level"); ok {
+		chunk = level
+	}
+
+	heightmaps, ok := chunk.Find("Heightmaps")
+
+	if !ok {
+		return
+	}
+
+	c, ok := heightmaps.Value.(nbt.Compound)
+
+	if !ok {
+		return nil, fmt.Errorf("chunk: Heightmaps is not a compound")
+	}
+
+	res = make(map[string]*Heightmap, len(c))
+
+	for name, tag := range c {
+		data, ok := tag.Value.([]int64)
+
+		if !ok {
+			return nil, fmt.Errorf("chunk: heightmap %s is not a long array", name)
+		}
+
+		if res[name], err = DecodeHeightmap(data, worldHeight, LayoutFor(dataVersion)); err != nil {
+			return nil, fmt.Errorf("chunk: heightmap %s: %w", name, err)
+		}
+	}
+
+	return
+}
+
+// HeightmapsTag returns a Heightmaps compound holding heightmaps, packed with the aligned layout.
+func HeightmapsTag(heightmaps map[string]*Heightmap, worldHeight int) *nbt.Tag {
+	c := make(nbt.Compound, len(heightmaps))
+
+	for name, h := range heightmaps {
+		c[name] = &nbt.Tag{Type: nbt.TypeLongArray, Name: []byte(name), Value: h.Encode(worldHeight, LayoutAligned)}
+	}
+
+	return &nbt.Tag{Type: nbt.TypeCompound, Name: []byte("Heightmaps"), Value: c}
+}
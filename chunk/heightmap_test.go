@@ -0,0 +1,72 @@
+package chunk
+
+import (
+	"testing"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+func TestHeightmapRoundTrip(t *testing.T) {
+	h := new(Heightmap)
+
+	for x := range 16 {
+		for z := range 16 {
+			h.Set(x, z, 64+x*z)
+		}
+	}
+
+	h.Set(15, 15, 384)
+
+	data := h.Encode(384, LayoutAligned)
+
+	// 7 heights of 9 bits per long
+	if len(data) != 37 {
+		t.Fatalf("expected 37 longs, got %d", len(data))
+	}
+
+	chunkTag := &nbt.Tag{Type: nbt.TypeCompound, Value: nbt.Compound{
+		"Heightmaps": HeightmapsTag(map[string]*Heightmap{HeightmapWorldSurface: h}, 384),
+	}}
+
+	res, err := ReadHeightmaps(chunkTag, 384, 3953)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	surface := res[HeightmapWorldSurface]
+
+	if surface == nil || *surface != *h {
+		t.Fatalf("heightmap differs after round trip")
+	}
+
+	if surface.At(15, 15) != 384 {
+		t.Fatalf("expected 384, got %d", surface.At(15, 15))
+	}
+}
+
+func TestLegacyHeightmap(t *testing.T) {
+	h := new(Heightmap)
+
+	for i := range h {
+		h[i] = i
+	}
+
+	level := &nbt.Tag{Type: nbt.TypeCompound, Name: []byte("Level"), Value: nbt.Compound{
+		"Heightmaps": {Type: nbt.TypeCompound, Name: []byte("Heightmaps"), Value: nbt.Compound{
+			HeightmapOceanFloor: {Type: nbt.TypeLongArray, Name: []byte(HeightmapOceanFloor), Value: h.Encode(256, LayoutSpanning)},
+		}},
+	}}
+
+	chunkTag := &nbt.Tag{Type: nbt.TypeCompound, Value: nbt.Compound{"Level": level}}
+
+	res, err := ReadHeightmaps(chunkTag, 256, 2230)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if floor := res[HeightmapOceanFloor]; floor == nil || floor.At(5, 3) != 3<<4|5 {
+		t.Fatalf("unexpected ocean floor heightmap %v", floor)
+	}
+}
@@ -0,0 +1,134 @@
+package chunk
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Layout is the way values are packed into a long array.
+type Layout int
+
+const (
+	// LayoutAligned packs as many values into each long as fit completely, leaving the remaining high bits unused.
+	// It's used since 1.16 (20w17a).
+	LayoutAligned Layout = iota
+	// LayoutSpanning packs values back to back, a value may start in one long and end in the next.
+	LayoutSpanning
+)
+
+// alignedDataVersion is the data version of 20w17a, the first version using LayoutAligned.
+const alignedDataVersion = 2529
+
+// LayoutFor returns the layout used by chunks with the given data version.
+func LayoutFor(dataVersion int32) Layout {
+	if dataVersion < alignedDataVersion {
+		return LayoutSpanning
+	}
+
+	return LayoutAligned
+}
+
+// BitsFor returns the number of bits needed to store indices into a palette of size entries, but at least minBits.
+func BitsFor(size int, minBits int) int {
+	n := 0
+
+	if size > 1 {
+		n = bits.Len(uint(size - 1))
+	}
+
+	return max(n, minBits)
+}
+
+// PackedLength returns the number of longs needed to store count values of n bits each.
+func PackedLength(count int, n int, layout Layout) int {
+	if n == 0 {
+		return 0
+	}
+
+	if layout == LayoutSpanning {
+		return (count*n + 63) / 64
+	}
+
+	perLong := 64 / n
+
+	return (count + perLong - 1) / perLong
+}
+
+// Unpack reads count values of n bits each from data.
+func Unpack(data []int64, count int, n int, layout Layout) (values []int, err error) {
+	if n < 0 || n > 32 {
+		return nil, fmt.Errorf("chunk: invalid bits per value: %d", n)
+	}
+
+	if expected := PackedLength(count, n, layout); len(data) < expected {
+		return nil, fmt.Errorf("chunk: expected %d longs for %d values of %d bits, got %d", expected, count, n, len(data))
+	}
+
+	values = make([]int, count)
+
+	if n == 0 {
+		return
+	}
+
+	mask := uint64(1)<<n - 1
+
+	if layout == LayoutSpanning {
+		for i := range count {
+			bit := i * n
+			word, offset := bit/64, bit%64
+
+			v := uint64(data[word]) >> offset
+
+			if offset+n > 64 {
+				v |= uint64(data[word+1]) << (64 - offset)
+			}
+
+			values[i] = int(v & mask)
+		}
+
+		return
+	}
+
+	perLong := 64 / n
+
+	for i := range count {
+		values[i] = int(uint64(data[i/perLong]) >> ((i % perLong) * n) & mask)
+	}
+
+	return
+}
+
+// Pack stores values with n bits each. Values have to be in [0, 2^n).
+func Pack(values []int, n int, layout Layout) (data []int64) {
+	data = make([]int64, PackedLength(len(values), n, layout))
+
+	if n == 0 {
+		return
+	}
+
+	mask := uint64(1)<<n - 1
+
+	if layout == LayoutSpanning {
+		for i, value := range values {
+			v := uint64(value) & mask
+			bit := i * n
+			word, offset := bit/64, bit%64
+
+			data[word] |= int64(v << offset)
+
+			if offset+n > 64 {
+				data[word+1] |= int64(v >> (64 - offset))
+			}
+		}
+
+		return
+	}
+
+	perLong := 64 / n
+
+	for i, value := range values {
+		data[i/perLong] |= int64((uint64(value) & mask) << ((i % perLong) * n))
+	}
+
+	return
+}
@@ -0,0 +1,93 @@
+package chunk
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestPackKnownValues(t *testing.T) {
+	values := make([]int, 16)
+
+	for i := range values {
+		values[i] = i
+	}
+
+	data := Pack(values, 4, LayoutAligned)
+
+	if len(data) != 1 || uint64(data[0]) != 0xfedcba9876543210 {
+		t.Fatalf("expected 0xfedcba9876543210, got %x", data)
+	}
+
+	// 13 values of 5 bits fill 65 bits, the last one spans into the second long
+	data = Pack(slices.Repeat([]int{31}, 13), 5, LayoutSpanning)
+
+	if len(data) != 2 || data[0] != -1 || data[1] != 1 {
+		t.Fatalf("expected [-1 1], got %v", data)
+	}
+
+	data = Pack(slices.Repeat([]int{31}, 13), 5, LayoutAligned)
+
+	if len(data) != 2 || uint64(data[0]) != 1<<60-1 || data[1] != 31 {
+		t.Fatalf("expected 12 values in the first long, got %x", data)
+	}
+}
+
+func TestPackRoundTrip(t *testing.T) {
+	for _, layout := range []Layout{LayoutAligned, LayoutSpanning} {
+		for n := 1; n <= 15; n++ {
+			values := make([]int, 4096)
+
+			for i := range values {
+				values[i] = rand.IntN(1 << n)
+			}
+
+			data := Pack(values, n, layout)
+
+			if len(data) != PackedLength(4096, n, layout) {
+				t.Fatalf("layout %d, %d bits: expected %d longs, got %d", layout, n, PackedLength(4096, n, layout), len(data))
+			}
+
+			res, err := Unpack(data, 4096, n, layout)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(values, res) {
+				t.Fatalf("layout %d, %d bits: values differ after round trip", layout, n)
+			}
+		}
+	}
+
+	if _, err := Unpack(make([]int64, 10), 4096, 4, LayoutAligned); err == nil {
+		t.Fatalf("expected error for short data")
+	}
+}
+
+func TestBitsFor(t *testing.T) {
+	cases := []struct{ size, min, expected int }{
+		{1, 0, 0},
+		{2, 0, 1},
+		{3, 0, 2},
+		{16, 4, 4},
+		{17, 4, 5},
+		{2, 4, 4},
+	}
+
+	for _, c := range cases {
+		if n := BitsFor(c.size, c.min); n != c.expected {
+			t.Fatalf("expected %d bits for %d entries, got %d", c.expected, c.size, n)
+		}
+	}
+}
+
+func TestLayoutFor(t *testing.T) {
+	if LayoutFor(2230) != LayoutSpanning {
+		t.Fatalf("expected spanning layout for 1.15")
+	}
+
+	if LayoutFor(2586) != LayoutAligned {
+		t.Fatalf("expected aligned layout for 1.16.5")
+	}
+}
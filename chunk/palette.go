@@ -0,0 +1,71 @@
+package chunk
+
+import (
+	"fmt"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+// decodePalette resolves count packed palette indices to their entries. A palette with a single entry needs no data.
+func decodePalette[T any](palette nbt.List, data []int64, count int, minBits int, layout Layout, entry func(*nbt.Tag) (T, error)) (res []T, err error) {
+	if len(palette) == 0 {
+		return nil, fmt.Errorf("chunk: empty palette")
+	}
+
+	entries := make([]T, len(palette))
+
+	for i, item := range palette {
+		if entries[i], err = entry(item); err != nil {
+			return
+		}
+	}
+
+	res = make([]T, count)
+
+	if len(palette) == 1 && len(data) == 0 {
+		for i := range res {
+			res[i] = entries[0]
+		}
+
+		return
+	}
+
+	indices, err := Unpack(data, count, BitsFor(len(palette), minBits), layout)
+
+	if err != nil {
+		return
+	}
+
+	for i, index := range indices {
+		if index >= len(entries) {
+			return nil, fmt.Errorf("chunk: palette index %d out of range at %d", index, i)
+		}
+
+		res[i] = entries[index]
+	}
+
+	return
+}
+
+// encodePalette builds a palette of the distinct values in order of first appearance and packs the indices into it.
+func encodePalette[T any](values []T, minBits int, layout Layout, key func(T) string, entry func(T) *nbt.Tag) (palette nbt.List, data []int64) {
+	lookup := map[string]int{}
+	indices := make([]int, len(values))
+
+	for i, v := range values {
+		k := key(v)
+		index, ok := lookup[k]
+
+		if !ok {
+			index = len(palette)
+			lookup[k] = index
+			palette = append(palette, entry(v))
+		}
+
+		indices[i] = index
+	}
+
+	data = Pack(indices, BitsFor(len(palette), minBits), layout)
+
+	return
+}
@@ -0,0 +1,366 @@
+// Package leveldbtest writes small LevelDB databases for tests of the leveldb reader.
+package leveldbtest
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Compression types of table blocks.
+const (
+	CompressionNone    = 0
+	CompressionZlib    = 2
+	CompressionZlibRaw = 4
+)
+
+const (
+	blockSize       = 512
+	restartInterval = 4
+	logBlockSize    = 32 * 1024
+)
+
+// Entry is a key written to a database. Deletions have no value.
+type Entry struct {
+	Key    []byte
+	Value  []byte
+	Delete bool
+}
+
+// Put returns an Entry setting key to value.
+func Put(key []byte, value []byte) Entry {
+	return Entry{Key: key, Value: value}
+}
+
+// Delete returns an Entry deleting key.
+func Delete(key []byte) Entry {
+	return Entry{Key: key, Delete: true}
+}
+
+// DB describes a database. Tables are written in order, each one overriding the previous ones, followed by the
+// log. Stale tables are written to disk but deleted from the MANIFEST again, like after a compaction.
+type DB struct {
+	Tables      [][]Entry
+	Stale       [][]Entry
+	Log         []Entry
+	Compression byte
+}
+
+// Write writes the database to dir.
+func (db *DB) Write(dir string) (err error) {
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+
+	var (
+		seq    uint64
+		number uint64 = 1
+		edit   []byte
+		stale  []byte
+	)
+
+	edit = appendUvarint(edit, 1)
+	edit = appendBytes(edit, []byte("leveldb.BytewiseComparator"))
+
+	writeTables := func(tables [][]Entry, edit []byte) ([]byte, error) {
+		for _, entries := range tables {
+			number++
+
+			path := filepath.Join(dir, fmt.Sprintf("%06d.ldb", number))
+			keys, data := db.table(entries, &seq)
+
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				return nil, err
+			}
+
+			edit = appendUvarint(edit, 7)
+			edit = appendUvarint(edit, 0)
+			edit = appendUvarint(edit, number)
+			edit = appendUvarint(edit, uint64(len(data)))
+			edit = appendBytes(edit, keys[0])
+			edit = appendBytes(edit, keys[len(keys)-1])
+		}
+
+		return edit, nil
+	}
+
+	if edit, err = writeTables(db.Stale, edit); err != nil {
+		return
+	}
+
+	staleNumbers := number
+
+	if edit, err = writeTables(db.Tables, edit); err != nil {
+		return
+	}
+
+	for n := uint64(2); n <= staleNumbers; n++ {
+		stale = appendUvarint(stale, 6)
+		stale = appendUvarint(stale, 0)
+		stale = appendUvarint(stale, n)
+	}
+
+	number++
+
+	logNumber := number
+
+	if err = os.WriteFile(filepath.Join(dir, fmt.Sprintf("%06d.log", logNumber)), logFile(batches(db.Log, &seq)), 0o644); err != nil {
+		return
+	}
+
+	edit = appendUvarint(edit, 2)
+	edit = appendUvarint(edit, logNumber)
+	edit = appendUvarint(edit, 3)
+	edit = appendUvarint(edit, number+1)
+	edit = appendUvarint(edit, 4)
+	edit = appendUvarint(edit, seq)
+
+	records := [][]byte{edit}
+
+	if len(stale) > 0 {
+		records = append(records, stale)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "MANIFEST-000001"), logFile(records), 0o644); err != nil {
+		return
+	}
+
+	return os.WriteFile(filepath.Join(dir, "CURRENT"), []byte("MANIFEST-000001\n"), 0o644)
+}
+
+// table returns the sorted internal keys and contents of a table holding entries.
+func (db *DB) table(entries []Entry, seq *uint64) (keys [][]byte, data []byte) {
+	type item struct {
+		key   []byte
+		value []byte
+	}
+
+	items := make([]item, 0, len(entries))
+
+	for _, e := range entries {
+		*seq++
+
+		items = append(items, item{key: internalKey(e, *seq), value: e.Value})
+	}
+
+	slices.SortFunc(items, func(a, b item) int {
+		if c := bytes.Compare(a.key[:len(a.key)-8], b.key[:len(b.key)-8]); c != 0 {
+			return c
+		}
+
+		// newer versions first
+		return bytes.Compare(b.key[len(b.key)-8:], a.key[len(a.key)-8:])
+	})
+
+	var index, block blockBuilder
+
+	for i, it := range items {
+		keys = append(keys, it.key)
+
+		block.add(it.key, it.value)
+
+		if block.size() >= blockSize || i == len(items)-1 {
+			data, index = db.flush(data, &block, index, it.key)
+		}
+	}
+
+	var meta blockBuilder
+
+	data, metaHandle := writeBlock(data, meta.finish(), CompressionNone)
+	data, indexHandle := writeBlock(data, index.finish(), CompressionNone)
+
+	footer := append(metaHandle, indexHandle...)
+	footer = append(footer, make([]byte, 40-len(footer))...)
+	footer = binary.LittleEndian.AppendUint64(footer, 0xdb4775248b80fb57)
+
+	return keys, append(data, footer...)
+}
+
+func (db *DB) flush(data []byte, block *blockBuilder, index blockBuilder, lastKey []byte) ([]byte, blockBuilder) {
+	data, handle := writeBlock(data, block.finish(), db.Compression)
+
+	index.add(lastKey, handle)
+
+	*block = blockBuilder{}
+
+	return data, index
+}
+
+func internalKey(e Entry, seq uint64) []byte {
+	kind := uint64(1)
+
+	if e.Delete {
+		kind = 0
+	}
+
+	return binary.LittleEndian.AppendUint64(slices.Clone(e.Key), seq<<8|kind)
+}
+
+// blockBuilder builds a block with prefix compressed keys.
+type blockBuilder struct {
+	buf      []byte
+	restarts []uint32
+	count    int
+	last     []byte
+}
+
+func (b *blockBuilder) add(key []byte, value []byte) {
+	shared := 0
+
+	if b.count%restartInterval == 0 {
+		b.restarts = append(b.restarts, uint32(len(b.buf)))
+	} else {
+		for shared < len(key) && shared < len(b.last) && key[shared] == b.last[shared] {
+			shared++
+		}
+	}
+
+	b.buf = appendUvarint(b.buf, uint64(shared))
+	b.buf = appendUvarint(b.buf, uint64(len(key)-shared))
+	b.buf = appendUvarint(b.buf, uint64(len(value)))
+	b.buf = append(b.buf, key[shared:]...)
+	b.buf = append(b.buf, value...)
+
+	b.last = key
+	b.count++
+}
+
+func (b *blockBuilder) size() int {
+	return len(b.buf)
+}
+
+func (b *blockBuilder) finish() []byte {
+	restarts := b.restarts
+
+	if len(restarts) == 0 {
+		restarts = []uint32{0}
+	}
+
+	res := slices.Clone(b.buf)
+
+	for _, r := range restarts {
+		res = binary.LittleEndian.AppendUint32(res, r)
+	}
+
+	return binary.LittleEndian.AppendUint32(res, uint32(len(restarts)))
+}
+
+// writeBlock appends the compressed block with its trailer to data and returns the encoded handle of the block.
+func writeBlock(data []byte, block []byte, compression byte) ([]byte, []byte) {
+	buf := &bytes.Buffer{}
+
+	switch compression {
+	case CompressionZlib:
+		w := zlib.NewWriter(buf)
+
+		_, _ = w.Write(block)
+		_ = w.Close()
+	case CompressionZlibRaw:
+		w, _ := flate.NewWriter(buf, flate.DefaultCompression)
+
+		_, _ = w.Write(block)
+		_ = w.Close()
+	default:
+		buf.Write(block)
+	}
+
+	handle := appendUvarint(nil, uint64(len(data)))
+	handle = appendUvarint(handle, uint64(buf.Len()))
+
+	data = append(data, buf.Bytes()...)
+	data = append(data, compression)
+	data = binary.LittleEndian.AppendUint32(data, maskedCRC(buf.Bytes(), []byte{compression}))
+
+	return data, handle
+}
+
+// batches returns a write batch record per entry.
+func batches(entries []Entry, seq *uint64) (records [][]byte) {
+	for _, e := range entries {
+		*seq++
+
+		r := binary.LittleEndian.AppendUint64(nil, *seq)
+		r = binary.LittleEndian.AppendUint32(r, 1)
+
+		if e.Delete {
+			r = append(r, 0)
+			r = appendBytes(r, e.Key)
+		} else {
+			r = append(r, 1)
+			r = appendBytes(r, e.Key)
+			r = appendBytes(r, e.Value)
+		}
+
+		records = append(records, r)
+	}
+
+	return
+}
+
+// logFile returns a log containing records, fragmenting them at block boundaries.
+func logFile(records [][]byte) (data []byte) {
+	for _, r := range records {
+		first := true
+
+		for {
+			left := logBlockSize - len(data)%logBlockSize
+
+			if left < 7 {
+				data = append(data, make([]byte, left)...)
+				left = logBlockSize
+			}
+
+			n := min(len(r), left-7)
+			last := n == len(r)
+
+			typ := byte(2)
+
+			switch {
+			case first && last:
+				typ = 1
+			case last:
+				typ = 4
+			case !first:
+				typ = 3
+			}
+
+			data = binary.LittleEndian.AppendUint32(data, maskedCRC([]byte{typ}, r[:n]))
+			data = binary.LittleEndian.AppendUint16(data, uint16(n))
+			data = append(data, typ)
+			data = append(data, r[:n]...)
+
+			r = r[n:]
+			first = false
+
+			if last {
+				break
+			}
+		}
+	}
+
+	return
+}
+
+func maskedCRC(data ...[]byte) uint32 {
+	var c uint32
+
+	for _, d := range data {
+		c = crc32.Update(c, crc32.MakeTable(crc32.Castagnoli), d)
+	}
+
+	return (c>>15 | c<<17) + 0xa282ead8
+}
+
+func appendUvarint(bs []byte, n uint64) []byte {
+	return binary.AppendUvarint(bs, n)
+}
+
+func appendBytes(bs []byte, data []byte) []byte {
+	return append(binary.AppendUvarint(bs, uint64(len(data))), data...)
+}
@@ -0,0 +1,103 @@
+package leveldb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// kinds of internal keys
+const (
+	kindDeletion = 0
+	kindValue    = 1
+)
+
+// entry is a single version of a key, as stored in tables and logs.
+type entry struct {
+	key   []byte
+	seq   uint64
+	kind  byte
+	value []byte
+}
+
+// parseInternalKey splits an internal key into the user key, sequence number and kind.
+func parseInternalKey(ikey []byte) (e entry, err error) {
+	if len(ikey) < 8 {
+		err = fmt.Errorf("leveldb: internal key too short: %d bytes", len(ikey))
+		return
+	}
+
+	n := len(ikey) - 8
+	trailer := binary.LittleEndian.Uint64(ikey[n:])
+
+	e.key = ikey[:n]
+	e.seq = trailer >> 8
+	e.kind = byte(trailer)
+
+	if e.kind > kindValue {
+		err = fmt.Errorf("leveldb: unknown key kind %d", e.kind)
+	}
+
+	return
+}
+
+// userKey returns the user key of an internal key.
+func userKey(ikey []byte) []byte {
+	if len(ikey) < 8 {
+		return ikey
+	}
+
+	return ikey[:len(ikey)-8]
+}
+
+// readBytes reads a byte slice prefixed with its uvarint length.
+func readBytes(data []byte) (bs []byte, rest []byte, err error) {
+	n, l := binary.Uvarint(data)
+
+	if l <= 0 || uint64(len(data)-l) < n {
+		err = errors.New("leveldb: truncated length prefixed bytes")
+		return
+	}
+
+	return data[l : l+int(n)], data[l+int(n):], nil
+}
+
+// readBatch returns the entries of a write batch as stored in log records.
+func readBatch(record []byte) (entries []entry, err error) {
+	if len(record) < 12 {
+		return nil, fmt.Errorf("leveldb: batch too short: %d bytes", len(record))
+	}
+
+	seq := binary.LittleEndian.Uint64(record[0:8])
+	count := binary.LittleEndian.Uint32(record[8:12])
+	data := record[12:]
+
+	// every entry takes at least two bytes, so a corrupt count can't allocate more than the record holds
+	entries = make([]entry, 0, min(count, uint32(len(data)/2)))
+
+	for i := range count {
+		if len(data) == 0 {
+			return nil, errors.New("leveldb: batch has fewer entries than announced")
+		}
+
+		e := entry{seq: seq + uint64(i), kind: data[0]}
+
+		if e.key, data, err = readBytes(data[1:]); err != nil {
+			return
+		}
+
+		switch e.kind {
+		case kindValue:
+			if e.value, data, err = readBytes(data); err != nil {
+				return
+			}
+		case kindDeletion:
+		default:
+			return nil, fmt.Errorf("leveldb: unknown batch entry kind %d", e.kind)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return
+}
@@ -0,0 +1,325 @@
+// Package leveldb reads LevelDB databases, like the ones Minecraft Bedrock Edition stores its worlds in. Besides
+// the formats of upstream LevelDB it understands the zlib compressed table blocks of Mojang's fork. Databases are
+// only read, another process must not write to a database while it is open.
+package leveldb
+
+import (
+	"bytes"
+	"cmp"
+	"container/heap"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned by Get if the key does not exist.
+var ErrNotFound = errors.New("leveldb: not found")
+
+// Entry is a key and its current value.
+type Entry struct {
+	Key   []byte
+	Value []byte
+}
+
+// DB is a LevelDB database opened for reading. It reflects the state at the time it was opened.
+type DB struct {
+	dir    string
+	files  []tableFile
+	tables map[uint64]*table
+	memory []entry
+}
+
+// Open opens the database in dir.
+func Open(dir string) (db *DB, err error) {
+	manifest, err := readCurrent(dir)
+
+	if err != nil {
+		return
+	}
+
+	v, err := readVersion(manifest)
+
+	if err != nil {
+		return
+	}
+
+	db = &DB{
+		dir:    dir,
+		tables: map[uint64]*table{},
+	}
+
+	for _, f := range v.files {
+		db.files = append(db.files, f)
+	}
+
+	// the order only matters for a stable iteration, sequence numbers decide which version of a key is current
+	slices.SortFunc(db.files, func(a, b tableFile) int {
+		return cmp.Compare(a.number, b.number)
+	})
+
+	if db.memory, err = readLogs(dir, v); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// readLogs replays the logs not yet compacted into tables and returns their entries sorted like in a table.
+func readLogs(dir string, v *version) (entries []entry, err error) {
+	names, err := os.ReadDir(dir)
+
+	if err != nil {
+		return
+	}
+
+	for _, name := range names {
+		number, ok := strings.CutSuffix(name.Name(), ".log")
+
+		if !ok {
+			continue
+		}
+
+		n, parseErr := strconv.ParseUint(number, 10, 64)
+
+		if parseErr != nil || (n < v.logNumber && n != v.prevLogNumber) {
+			continue
+		}
+
+		var records [][]byte
+
+		if records, err = readLogFile(filepath.Join(dir, name.Name())); err != nil {
+			return
+		}
+
+		for _, record := range records {
+			var batch []entry
+
+			if batch, err = readBatch(record); err != nil {
+				return
+			}
+
+			entries = append(entries, batch...)
+		}
+	}
+
+	slices.SortFunc(entries, compareEntries)
+
+	return
+}
+
+// compareEntries orders entries by key and newer versions of a key first.
+func compareEntries(a, b entry) int {
+	if c := bytes.Compare(a.key, b.key); c != 0 {
+		return c
+	}
+
+	if a.seq > b.seq {
+		return -1
+	}
+
+	if a.seq < b.seq {
+		return 1
+	}
+
+	return 0
+}
+
+// Close closes all table files.
+func (db *DB) Close() (err error) {
+	for _, t := range db.tables {
+		err = errors.Join(err, t.close())
+	}
+
+	clear(db.tables)
+
+	return
+}
+
+// table returns the open table f, opening it on first use.
+func (db *DB) table(f tableFile) (t *table, err error) {
+	if t = db.tables[f.number]; t != nil {
+		return
+	}
+
+	name := fmt.Sprintf("%06d", f.number)
+
+	// the .sst extension is used by old versions of LevelDB
+	if t, err = openTable(filepath.Join(db.dir, name+".ldb")); errors.Is(err, os.ErrNotExist) {
+		t, err = openTable(filepath.Join(db.dir, name+".sst"))
+	}
+
+	if err != nil {
+		return
+	}
+
+	db.tables[f.number] = t
+
+	return
+}
+
+// Get returns the value of key.
+func (db *DB) Get(key []byte) (value []byte, err error) {
+	for e, err := range db.Entries(key) {
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.Equal(e.Key, key) {
+			return e.Value, nil
+		}
+
+		break
+	}
+
+	return nil, ErrNotFound
+}
+
+// Entries yields all entries whose key starts with prefix, in key order. The iteration ends after the first error.
+func (db *DB) Entries(prefix []byte) iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		it, err := db.merge(prefix)
+
+		if err != nil {
+			yield(Entry{}, err)
+			return
+		}
+
+		var last []byte
+
+		for {
+			e, ok, err := it.next()
+
+			if err != nil {
+				yield(Entry{}, err)
+				return
+			}
+
+			if !ok || !bytes.HasPrefix(e.key, prefix) {
+				return
+			}
+
+			// only the first, newest version of a key counts
+			if last != nil && bytes.Equal(e.key, last) {
+				continue
+			}
+
+			last = e.key
+
+			if e.kind == kindDeletion {
+				continue
+			}
+
+			if !yield(Entry{Key: e.key, Value: e.value}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// merge returns an iterator over all versions of all keys not less than start, reading only the tables that may
+// contain keys starting with start.
+func (db *DB) merge(start []byte) (m *mergeIterator, err error) {
+	m = &mergeIterator{}
+
+	i := sort.Search(len(db.memory), func(i int) bool {
+		return bytes.Compare(db.memory[i].key, start) >= 0
+	})
+
+	if err = m.add(&memoryIterator{entries: db.memory[i:]}); err != nil {
+		return
+	}
+
+	for _, f := range db.files {
+		smallest, largest := userKey(f.smallest), userKey(f.largest)
+
+		if bytes.Compare(largest, start) < 0 || (bytes.Compare(smallest, start) > 0 && !bytes.HasPrefix(smallest, start)) {
+			continue
+		}
+
+		var t *table
+
+		if t, err = db.table(f); err != nil {
+			return
+		}
+
+		if err = m.add(t.iterator(start)); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+type iterator interface {
+	next() (e entry, ok bool, err error)
+}
+
+// memoryIterator iterates over the sorted entries replayed from the logs.
+type memoryIterator struct {
+	entries []entry
+}
+
+func (it *memoryIterator) next() (e entry, ok bool, err error) {
+	if len(it.entries) == 0 {
+		return
+	}
+
+	e, it.entries = it.entries[0], it.entries[1:]
+
+	return e, true, nil
+}
+
+// mergeIterator merges several sorted iterators into one.
+type mergeIterator struct {
+	heads []mergeHead
+}
+
+type mergeHead struct {
+	e  entry
+	it iterator
+}
+
+func (m *mergeIterator) Len() int           { return len(m.heads) }
+func (m *mergeIterator) Less(i, j int) bool { return compareEntries(m.heads[i].e, m.heads[j].e) < 0 }
+func (m *mergeIterator) Swap(i, j int)      { m.heads[i], m.heads[j] = m.heads[j], m.heads[i] }
+func (m *mergeIterator) Push(x any)         { m.heads = append(m.heads, x.(mergeHead)) }
+
+func (m *mergeIterator) Pop() any {
+	h := m.heads[len(m.heads)-1]
+
+	m.heads = m.heads[:len(m.heads)-1]
+
+	return h
+}
+
+func (m *mergeIterator) add(it iterator) error {
+	e, ok, err := it.next()
+
+	if err != nil || !ok {
+		return err
+	}
+
+	heap.Push(m, mergeHead{e: e, it: it})
+
+	return nil
+}
+
+func (m *mergeIterator) next() (e entry, ok bool, err error) {
+	if len(m.heads) == 0 {
+		return
+	}
+
+	head := heap.Pop(m).(mergeHead)
+
+	if err = m.add(head.it); err != nil {
+		return
+	}
+
+	return head.e, true, nil
+}
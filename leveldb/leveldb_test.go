@@ -0,0 +1,232 @@
+package leveldb
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/nitwhiz/go-nbt/internal/leveldbtest"
+)
+
+func key(i int) []byte {
+	return []byte(fmt.Sprintf("key%04d", i))
+}
+
+func writeTestDB(t *testing.T, compression byte) *DB {
+	t.Helper()
+
+	var older, newer []leveldbtest.Entry
+
+	for i := range 200 {
+		older = append(older, leveldbtest.Put(key(i), []byte(fmt.Sprintf("old%d", i))))
+	}
+
+	// the newer table overrides every second key and deletes every tenth
+	for i := 0; i < 200; i += 2 {
+		if i%10 == 0 {
+			newer = append(newer, leveldbtest.Delete(key(i)))
+		} else {
+			newer = append(newer, leveldbtest.Put(key(i), []byte(fmt.Sprintf("new%d", i))))
+		}
+	}
+
+	dir := t.TempDir()
+
+	db := &leveldbtest.DB{
+		Tables: [][]leveldbtest.Entry{older, newer},
+		Stale:  [][]leveldbtest.Entry{{leveldbtest.Put([]byte("stale"), []byte("x"))}},
+		Log: []leveldbtest.Entry{
+			leveldbtest.Put(key(3), []byte("log3")),
+			leveldbtest.Delete(key(5)),
+			leveldbtest.Put(key(10), []byte("log10")),
+			leveldbtest.Put([]byte("large"), bytes.Repeat([]byte{'x'}, 100_000)),
+		},
+		Compression: compression,
+	}
+
+	if err := db.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Open(dir)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = res.Close()
+	})
+
+	return res
+}
+
+func expectedValue(i int) (string, bool) {
+	switch {
+	case i == 3:
+		return "log3", true
+	case i == 5:
+		return "", false
+	case i == 10:
+		return "log10", true
+	case i%10 == 0:
+		return "", false
+	case i%2 == 0:
+		return fmt.Sprintf("new%d", i), true
+	default:
+		return fmt.Sprintf("old%d", i), true
+	}
+}
+
+func TestGet(t *testing.T) {
+	for _, c := range []byte{leveldbtest.CompressionNone, leveldbtest.CompressionZlib, leveldbtest.CompressionZlibRaw} {
+		t.Run(fmt.Sprintf("compression %d", c), func(t *testing.T) {
+			db := writeTestDB(t, c)
+
+			for i := range 200 {
+				expected, exists := expectedValue(i)
+
+				value, err := db.Get(key(i))
+
+				if !exists {
+					if !errors.Is(err, ErrNotFound) {
+						t.Fatalf("expected %s to be deleted, got %q, %v", key(i), value, err)
+					}
+
+					continue
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(value) != expected {
+					t.Fatalf("expected %s to be %q, got %q", key(i), expected, value)
+				}
+			}
+
+			if _, err := db.Get([]byte("stale")); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected stale table to be ignored, got %v", err)
+			}
+
+			if _, err := db.Get([]byte("key")); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected prefix of existing keys not to be found, got %v", err)
+			}
+
+			value, err := db.Get([]byte("large"))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(value) != 100_000 {
+				t.Fatalf("expected fragmented log record of 100000 bytes, got %d", len(value))
+			}
+		})
+	}
+}
+
+func TestEntries(t *testing.T) {
+	db := writeTestDB(t, leveldbtest.CompressionZlibRaw)
+
+	var keys [][]byte
+
+	for e, err := range db.Entries([]byte("key01")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keys = append(keys, e.Key)
+	}
+
+	// key0100 to key0199 without the deleted ones
+	if len(keys) != 90 {
+		t.Fatalf("expected 90 entries, got %d", len(keys))
+	}
+
+	for i := 1; i < len(keys); i++ {
+		if bytes.Compare(keys[i-1], keys[i]) >= 0 {
+			t.Fatalf("expected ascending keys, got %s before %s", keys[i-1], keys[i])
+		}
+	}
+
+	count := 0
+
+	for _, err := range db.Entries(nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		count++
+	}
+
+	// 200 keys, 19 deleted in tables (key0010 is put again), key0005 deleted in the log and the large one
+	if count != 181 {
+		t.Fatalf("expected 181 entries, got %d", count)
+	}
+}
+
+func TestDecompressLimit(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, _ := flate.NewWriter(buf, flate.BestSpeed)
+
+	if _, err := w.Write(make([]byte, maxBlockSize+1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := decompress(compressionZlibRaw, buf.Bytes()); err == nil {
+		t.Fatalf("expected error for block inflating beyond %d bytes", maxBlockSize)
+	}
+}
+
+func logRecord(typ byte, payload []byte) []byte {
+	bs := binary.LittleEndian.AppendUint32(nil, maskedCRC([]byte{typ}, payload))
+	bs = binary.LittleEndian.AppendUint16(bs, uint16(len(payload)))
+	bs = append(bs, typ)
+
+	return append(bs, payload...)
+}
+
+func TestLogTornTail(t *testing.T) {
+	data := logRecord(recordFull, []byte("first"))
+	torn := logRecord(recordFull, []byte("second"))
+
+	torn[0] ^= 0xff
+	data = append(data, torn...)
+
+	records, err := readLog(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 1 || string(records[0]) != "first" {
+		t.Fatalf("expected [first], got %q", records)
+	}
+
+	// a corrupt record followed by more blocks is not a torn tail
+	data = append(data, make([]byte, logBlockSize-len(data))...)
+	data = append(data, logRecord(recordFull, []byte("third"))...)
+
+	if _, err := readLog(data); err == nil {
+		t.Fatalf("expected checksum error for a corrupt record before the last block")
+	}
+}
+
+func TestBatchHugeCount(t *testing.T) {
+	record := binary.LittleEndian.AppendUint64(nil, 1)
+	record = binary.LittleEndian.AppendUint32(record, math.MaxUint32)
+	record = append(record, kindDeletion, 1, 'k')
+
+	if _, err := readBatch(record); err == nil {
+		t.Fatalf("expected error for batch with fewer entries than announced")
+	}
+}
@@ -0,0 +1,119 @@
+package leveldb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+)
+
+const (
+	logBlockSize  = 32 * 1024
+	logHeaderSize = 7
+)
+
+// record types of the log format, used by .log files and the MANIFEST
+const (
+	recordFull   = 1
+	recordFirst  = 2
+	recordMiddle = 3
+	recordLast   = 4
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// maskedCRC returns the checksum of data as stored by LevelDB, masked so checksums of data containing checksums
+// stay well distributed.
+func maskedCRC(data ...[]byte) uint32 {
+	var c uint32
+
+	for _, d := range data {
+		c = crc32.Update(c, crcTable, d)
+	}
+
+	return (c>>15 | c<<17) + 0xa282ead8
+}
+
+// readLog splits the contents of a log file into its records, joining fragmented ones. A truncated or corrupt record
+// in the last block, left behind by a crash while writing, ends the log without error.
+func readLog(data []byte) (records [][]byte, err error) {
+	var pending []byte
+
+	fragmented := false
+
+	for offset := 0; offset < len(data); {
+		blockLeft := logBlockSize - offset%logBlockSize
+
+		// the remainder of a block too small for a header is padding
+		if blockLeft < logHeaderSize {
+			offset += blockLeft
+			continue
+		}
+
+		if offset+logHeaderSize > len(data) {
+			return
+		}
+
+		header := data[offset : offset+logHeaderSize]
+		checksum := binary.LittleEndian.Uint32(header[0:4])
+		length := int(binary.LittleEndian.Uint16(header[4:6]))
+		typ := header[6]
+
+		// preallocated files are zero filled
+		if typ == 0 && length == 0 {
+			return
+		}
+
+		start := offset + logHeaderSize
+
+		if start+length > len(data) {
+			return
+		}
+
+		payload := data[start : start+length]
+
+		if maskedCRC(header[6:7], payload) != checksum {
+			if offset/logBlockSize == (len(data)-1)/logBlockSize {
+				return
+			}
+
+			return nil, fmt.Errorf("leveldb: log record checksum mismatch at offset %d", offset)
+		}
+
+		offset = start + length
+
+		switch typ {
+		case recordFull:
+			records = append(records, payload)
+		case recordFirst:
+			pending, fragmented = append([]byte{}, payload...), true
+		case recordMiddle, recordLast:
+			if !fragmented {
+				return nil, errors.New("leveldb: log record fragment without start")
+			}
+
+			pending = append(pending, payload...)
+
+			if typ == recordLast {
+				records = append(records, pending)
+				pending, fragmented = nil, false
+			}
+		default:
+			return nil, fmt.Errorf("leveldb: unknown log record type %d", typ)
+		}
+	}
+
+	return
+}
+
+// readLogFile reads all records of the log file at path.
+func readLogFile(path string) ([][]byte, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return readLog(data)
+}
@@ -0,0 +1,144 @@
+package leveldb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tags of the fields of a version edit
+const (
+	editComparator     = 1
+	editLogNumber      = 2
+	editNextFileNumber = 3
+	editLastSequence   = 4
+	editCompactPointer = 5
+	editDeletedFile    = 6
+	editNewFile        = 7
+	editPrevLogNumber  = 9
+)
+
+const bytewiseComparator = "leveldb.BytewiseComparator"
+
+// tableFile describes a table file of the current version.
+type tableFile struct {
+	level    int
+	number   uint64
+	size     uint64
+	smallest []byte
+	largest  []byte
+}
+
+// version is the set of live files, built by replaying the version edits of the MANIFEST.
+type version struct {
+	logNumber     uint64
+	prevLogNumber uint64
+	files         map[uint64]tableFile
+}
+
+// readCurrent returns the path of the MANIFEST named in the CURRENT file of dir.
+func readCurrent(dir string) (string, error) {
+	bs, err := os.ReadFile(filepath.Join(dir, "CURRENT"))
+
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSpace(string(bs))
+
+	if !strings.HasPrefix(name, "MANIFEST-") || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("leveldb: invalid CURRENT file: %q", name)
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+// readVersion replays all version edits of the MANIFEST at path.
+func readVersion(path string) (v *version, err error) {
+	records, err := readLogFile(path)
+
+	if err != nil {
+		return
+	}
+
+	v = &version{files: map[uint64]tableFile{}}
+
+	for _, record := range records {
+		if err = v.apply(record); err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// apply applies a single version edit to v.
+func (v *version) apply(edit []byte) (err error) {
+	uvarint := func() (n uint64) {
+		if err != nil {
+			return
+		}
+
+		var l int
+
+		if n, l = binary.Uvarint(edit); l <= 0 {
+			err = errors.New("leveldb: truncated version edit")
+			return
+		}
+
+		edit = edit[l:]
+
+		return
+	}
+
+	prefixed := func() (bs []byte) {
+		if err != nil {
+			return
+		}
+
+		bs, edit, err = readBytes(edit)
+
+		return
+	}
+
+	for len(edit) > 0 && err == nil {
+		switch tag := uvarint(); tag {
+		case editComparator:
+			if name := string(prefixed()); err == nil && name != bytewiseComparator {
+				return fmt.Errorf("leveldb: unsupported comparator %q", name)
+			}
+		case editLogNumber:
+			v.logNumber = uvarint()
+		case editPrevLogNumber:
+			v.prevLogNumber = uvarint()
+		case editNextFileNumber, editLastSequence:
+			uvarint()
+		case editCompactPointer:
+			uvarint()
+			prefixed()
+		case editDeletedFile:
+			uvarint()
+			delete(v.files, uvarint())
+		case editNewFile:
+			f := tableFile{
+				level:  int(uvarint()),
+				number: uvarint(),
+				size:   uvarint(),
+			}
+
+			f.smallest = prefixed()
+			f.largest = prefixed()
+
+			v.files[f.number] = f
+		default:
+			if err == nil {
+				err = fmt.Errorf("leveldb: unknown version edit tag %d", tag)
+			}
+		}
+	}
+
+	return
+}
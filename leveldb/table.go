@@ -0,0 +1,296 @@
+package leveldb
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	footerSize   = 48
+	tableMagic   = 0xdb4775248b80fb57
+	trailerSize  = 5
+	maxBlockSize = 64 << 20
+)
+
+// compression types of table blocks, Mojang's LevelDB adds the zlib based ones
+const (
+	compressionNone    = 0
+	compressionSnappy  = 1
+	compressionZlib    = 2
+	compressionZlibRaw = 4
+)
+
+// blockHandle locates a block in a table file.
+type blockHandle struct {
+	offset uint64
+	size   uint64
+}
+
+func readBlockHandle(data []byte) (h blockHandle, rest []byte, err error) {
+	var l int
+
+	if h.offset, l = binary.Uvarint(data); l <= 0 {
+		err = errors.New("leveldb: invalid block handle")
+		return
+	}
+
+	data = data[l:]
+
+	if h.size, l = binary.Uvarint(data); l <= 0 {
+		err = errors.New("leveldb: invalid block handle")
+		return
+	}
+
+	rest = data[l:]
+
+	return
+}
+
+// kv is a single entry of a block.
+type kv struct {
+	key   []byte
+	value []byte
+}
+
+// table is an open table file. The index is read when the table is opened, data blocks are read on demand.
+type table struct {
+	f     *os.File
+	index []kv
+}
+
+func openTable(path string) (t *table, err error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return
+	}
+
+	t = &table{f: f}
+
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			t = nil
+		}
+	}()
+
+	info, err := f.Stat()
+
+	if err != nil {
+		return
+	}
+
+	if info.Size() < footerSize {
+		return nil, fmt.Errorf("leveldb: table %s too short", path)
+	}
+
+	footer := make([]byte, footerSize)
+
+	if _, err = f.ReadAt(footer, info.Size()-footerSize); err != nil {
+		return
+	}
+
+	if binary.LittleEndian.Uint64(footer[40:]) != tableMagic {
+		return nil, fmt.Errorf("leveldb: table %s has bad magic number", path)
+	}
+
+	// the metaindex handle comes first, only filters are stored there
+	_, rest, err := readBlockHandle(footer)
+
+	if err != nil {
+		return
+	}
+
+	indexHandle, _, err := readBlockHandle(rest)
+
+	if err != nil {
+		return
+	}
+
+	t.index, err = t.readBlock(indexHandle)
+
+	return
+}
+
+func (t *table) close() error {
+	return t.f.Close()
+}
+
+// readBlock reads, verifies and decompresses the block at h and returns its entries.
+func (t *table) readBlock(h blockHandle) (entries []kv, err error) {
+	if h.size > maxBlockSize {
+		return nil, fmt.Errorf("leveldb: block too large: %d bytes", h.size)
+	}
+
+	raw := make([]byte, h.size+trailerSize)
+
+	if _, err = t.f.ReadAt(raw, int64(h.offset)); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return
+	}
+
+	data, trailer := raw[:h.size], raw[h.size:]
+
+	if maskedCRC(data, trailer[:1]) != binary.LittleEndian.Uint32(trailer[1:]) {
+		return nil, fmt.Errorf("leveldb: block checksum mismatch at offset %d", h.offset)
+	}
+
+	if data, err = decompress(trailer[0], data); err != nil {
+		return
+	}
+
+	return parseBlock(data)
+}
+
+func decompress(c byte, data []byte) ([]byte, error) {
+	var r io.ReadCloser
+
+	switch c {
+	case compressionNone:
+		return data, nil
+	case compressionZlib:
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+
+		if err != nil {
+			return nil, err
+		}
+
+		r = zr
+	case compressionZlibRaw:
+		r = flate.NewReader(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("leveldb: unsupported block compression %d", c)
+	}
+
+	defer func(r io.ReadCloser) {
+		_ = r.Close()
+	}(r)
+
+	// blocks are small, a corrupt block must not inflate without bounds
+	data, err := io.ReadAll(io.LimitReader(r, maxBlockSize+1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxBlockSize {
+		return nil, fmt.Errorf("leveldb: decompressed block larger than %d bytes", maxBlockSize)
+	}
+
+	return data, nil
+}
+
+// parseBlock returns the entries of an uncompressed block. Keys are stored with the prefix they share with the
+// previous key removed, followed by the restart points which are not needed for a full scan.
+func parseBlock(data []byte) (entries []kv, err error) {
+	if len(data) < 4 {
+		return nil, errors.New("leveldb: block too short")
+	}
+
+	restarts := binary.LittleEndian.Uint32(data[len(data)-4:])
+	end := len(data) - 4 - 4*int(restarts)
+
+	if restarts == 0 || end < 0 {
+		return nil, errors.New("leveldb: invalid block restart count")
+	}
+
+	data = data[:end]
+
+	var key []byte
+
+	for len(data) > 0 {
+		var header [3]uint64
+
+		for i := range header {
+			n, l := binary.Uvarint(data)
+
+			if l <= 0 {
+				return nil, errors.New("leveldb: invalid block entry")
+			}
+
+			header[i] = n
+			data = data[l:]
+		}
+
+		shared, unshared, valueLength := header[0], header[1], header[2]
+
+		if shared > uint64(len(key)) || unshared+valueLength > uint64(len(data)) {
+			return nil, errors.New("leveldb: invalid block entry")
+		}
+
+		// the key is copied, the previous one is still referenced by the last entry
+		key = append(key[:shared:shared], data[:unshared]...)
+
+		entries = append(entries, kv{key: key, value: data[unshared : unshared+valueLength]})
+		data = data[unshared+valueLength:]
+	}
+
+	return
+}
+
+// tableIterator yields the entries of a table in order, starting at the first key not less than start.
+type tableIterator struct {
+	t       *table
+	start   []byte
+	block   int
+	entries []kv
+	pos     int
+}
+
+func (t *table) iterator(start []byte) *tableIterator {
+	// index keys are at least as large as the last key of their block
+	block := sort.Search(len(t.index), func(i int) bool {
+		return bytes.Compare(userKey(t.index[i].key), start) >= 0
+	})
+
+	return &tableIterator{t: t, start: start, block: block}
+}
+
+func (it *tableIterator) next() (e entry, ok bool, err error) {
+	for {
+		if it.pos < len(it.entries) {
+			item := it.entries[it.pos]
+
+			it.pos++
+
+			if e, err = parseInternalKey(item.key); err != nil {
+				return
+			}
+
+			if bytes.Compare(e.key, it.start) < 0 {
+				continue
+			}
+
+			e.value = item.value
+
+			return e, true, nil
+		}
+
+		if it.block >= len(it.t.index) {
+			return
+		}
+
+		var h blockHandle
+
+		if h, _, err = readBlockHandle(it.t.index[it.block].value); err != nil {
+			return
+		}
+
+		if it.entries, err = it.t.readBlock(h); err != nil {
+			return
+		}
+
+		it.block++
+		it.pos = 0
+	}
+}
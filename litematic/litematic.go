@@ -0,0 +1,403 @@
+package litematic
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/chunk"
+	"github.com/nitwhiz/go-nbt/nbt"
+	"github.com/nitwhiz/go-nbt/volume"
+)
+
+// Version is the format version written by Litematica 0.15 and later.
+const Version = 6
+
+// minBits is the minimum bits per block state index Litematica packs with.
+const minBits = 2
+
+// Litematic is a Litematica schematic with one or more named regions.
+type Litematic struct {
+	Version     int32
+	SubVersion  int32
+	DataVersion int32
+	Metadata    Metadata
+	Regions     map[string]*Region
+}
+
+// Metadata describes a schematic. RegionCount, TotalBlocks, TotalVolume and EnclosingSize are updated on Write.
+type Metadata struct {
+	Name             string       `nbt:"Name"`
+	Author           string       `nbt:"Author"`
+	Description      string       `nbt:"Description"`
+	RegionCount      int32        `nbt:"RegionCount"`
+	TimeCreated      int64        `nbt:"TimeCreated"`
+	TimeModified     int64        `nbt:"TimeModified"`
+	TotalBlocks      int32        `nbt:"TotalBlocks"`
+	TotalVolume      int32        `nbt:"TotalVolume"`
+	EnclosingSize    Vec3         `nbt:"EnclosingSize"`
+	PreviewImageData nbt.IntArray `nbt:"PreviewImageData,omitempty"`
+	Rest             nbt.Compound `nbt:",rest"`
+}
+
+// Created returns TimeCreated as time.
+func (m *Metadata) Created() time.Time {
+	return time.UnixMilli(m.TimeCreated)
+}
+
+// Modified returns TimeModified as time.
+func (m *Metadata) Modified() time.Time {
+	return time.UnixMilli(m.TimeModified)
+}
+
+type Vec3 struct {
+	X int32 `nbt:"x"`
+	Y int32 `nbt:"y"`
+	Z int32 `nbt:"z"`
+}
+
+func (v Vec3) array() [3]int {
+	return [3]int{int(v.X), int(v.Y), int(v.Z)}
+}
+
+func vec3(a [3]int) Vec3 {
+	return Vec3{X: int32(a[0]), Y: int32(a[1]), Z: int32(a[2])}
+}
+
+// Region is a named box of blocks in a schematic.
+type Region struct {
+	// Position is the corner the region was selected from, relative to the schematic origin.
+	Position [3]int
+	// Size is the extent from Position along each axis. Negative components extend towards smaller coordinates.
+	Size [3]int
+	// Volume holds the blocks, positions are relative to Min.
+	Volume            *volume.Volume
+	PendingBlockTicks nbt.List
+	PendingFluidTicks nbt.List
+	Rest              nbt.Compound
+}
+
+// Min returns the corner of r with the smallest coordinates, relative to the schematic origin.
+func (r *Region) Min() (res [3]int) {
+	for i := range 3 {
+		res[i] = r.Position[i]
+
+		if r.Size[i] < 0 {
+			res[i] += r.Size[i] + 1
+		}
+	}
+
+	return
+}
+
+// Max returns the corner of r with the largest coordinates, relative to the schematic origin.
+func (r *Region) Max() (res [3]int) {
+	for i := range 3 {
+		res[i] = r.Position[i]
+
+		if r.Size[i] > 0 {
+			res[i] += r.Size[i] - 1
+		}
+	}
+
+	return
+}
+
+// At returns the block state at x, y, z relative to Min.
+func (r *Region) At(x, y, z int) block.State {
+	return r.Volume.At(x, y, z)
+}
+
+// NewRegion returns a region at position holding v.
+func NewRegion(position [3]int, v *volume.Volume) *Region {
+	return &Region{Position: position, Size: v.Size, Volume: v}
+}
+
+// file is the NBT layout of a litematic.
+type file struct {
+	Version     int32        `nbt:"Version"`
+	SubVersion  int32        `nbt:"SubVersion,omitempty"`
+	DataVersion int32        `nbt:"MinecraftDataVersion"`
+	Metadata    Metadata     `nbt:"Metadata"`
+	Regions     nbt.Compound `nbt:"Regions"`
+}
+
+type fileRegion struct {
+	Position          Vec3           `nbt:"Position"`
+	Size              Vec3           `nbt:"Size"`
+	BlockStatePalette nbt.List       `nbt:"BlockStatePalette"`
+	BlockStates       nbt.LongArray  `nbt:"BlockStates"`
+	TileEntities      []nbt.Compound `nbt:"TileEntities"`
+	Entities          []nbt.Compound `nbt:"Entities"`
+	PendingBlockTicks nbt.List       `nbt:"PendingBlockTicks"`
+	PendingFluidTicks nbt.List       `nbt:"PendingFluidTicks"`
+	Rest              nbt.Compound   `nbt:",rest"`
+}
+
+// Read reads a litematic, gzip compressed or not.
+func Read(r io.Reader) (l *Litematic, err error) {
+	root := &nbt.Tag{}
+
+	if err = nbt.NewDecoder(r).Decode(root); err != nil {
+		return
+	}
+
+	f := file{}
+
+	if err = nbt.UnmarshalTag(&f, root); err != nil {
+		return
+	}
+
+	if f.Regions == nil {
+		return nil, errors.New("litematic: no regions")
+	}
+
+	l = &Litematic{
+		Version:     f.Version,
+		SubVersion:  f.SubVersion,
+		DataVersion: f.DataVersion,
+		Metadata:    f.Metadata,
+		Regions:     make(map[string]*Region, len(f.Regions)),
+	}
+
+	for name, tag := range f.Regions {
+		fr := fileRegion{}
+
+		if err = nbt.UnmarshalTag(&fr, tag); err != nil {
+			return nil, err
+		}
+
+		if l.Regions[name], err = readRegion(&fr); err != nil {
+			return nil, fmt.Errorf("litematic: region %s: %w", name, err)
+		}
+	}
+
+	return
+}
+
+func readRegion(fr *fileRegion) (r *Region, err error) {
+	r = &Region{
+		Position:          fr.Position.array(),
+		Size:              fr.Size.array(),
+		PendingBlockTicks: fr.PendingBlockTicks,
+		PendingFluidTicks: fr.PendingFluidTicks,
+		Rest:              fr.Rest,
+	}
+
+	size := [3]int{}
+
+	for i, n := range r.Size {
+		size[i] = max(n, -n)
+	}
+
+	r.Volume = volume.New(size[0], size[1], size[2])
+
+	if len(fr.BlockStatePalette) == 0 {
+		return nil, errors.New("empty palette")
+	}
+
+	r.Volume.Palette = make([]block.State, len(fr.BlockStatePalette))
+
+	for i, entry := range fr.BlockStatePalette {
+		if r.Volume.Palette[i], err = block.StateFromTag(entry); err != nil {
+			return
+		}
+	}
+
+	bits := chunk.BitsFor(len(r.Volume.Palette), minBits)
+
+	if r.Volume.Indices, err = chunk.Unpack(fr.BlockStates, len(r.Volume.Indices), bits, chunk.LayoutSpanning); err != nil {
+		return
+	}
+
+	for _, index := range r.Volume.Indices {
+		if index >= len(r.Volume.Palette) {
+			return nil, fmt.Errorf("palette index %d out of range", index)
+		}
+	}
+
+	for _, te := range fr.TileEntities {
+		pos := [3]int{}
+
+		for i, name := range [3]string{"x", "y", "z"} {
+			v, ok := te[name]
+
+			if !ok || v.Type != nbt.TypeInt {
+				return nil, errors.New("tile entity without position")
+			}
+
+			pos[i] = int(v.Value.(int32))
+		}
+
+		r.Volume.BlockEntities[pos] = without(te, "x", "y", "z")
+	}
+
+	for _, e := range fr.Entities {
+		pos := [3]float64{}
+		l, _ := e["Pos"].Value.(nbt.List)
+
+		if len(l) != 3 {
+			return nil, errors.New("entity without position")
+		}
+
+		for i, item := range l {
+			pos[i], _ = item.Value.(float64)
+		}
+
+		r.Volume.Entities = append(r.Volume.Entities, volume.Entity{Pos: pos, NBT: without(e, "Pos")})
+	}
+
+	return
+}
+
+// without returns a copy of c without the given entries.
+func without(c nbt.Compound, names ...string) nbt.Compound {
+	res := make(nbt.Compound, len(c))
+
+	for name, tag := range c {
+		res[name] = tag
+	}
+
+	for _, name := range names {
+		delete(res, name)
+	}
+
+	return res
+}
+
+func (r *Region) file() *fileRegion {
+	v := r.Volume
+
+	fr := &fileRegion{
+		Position:          vec3(r.Position),
+		Size:              vec3(r.Size),
+		BlockStatePalette: make(nbt.List, len(v.Palette)),
+		BlockStates:       chunk.Pack(v.Indices, chunk.BitsFor(len(v.Palette), minBits), chunk.LayoutSpanning),
+		TileEntities:      make([]nbt.Compound, 0, len(v.BlockEntities)),
+		Entities:          make([]nbt.Compound, 0, len(v.Entities)),
+		PendingBlockTicks: r.PendingBlockTicks,
+		PendingFluidTicks: r.PendingFluidTicks,
+		Rest:              r.Rest,
+	}
+
+	if fr.PendingBlockTicks == nil {
+		fr.PendingBlockTicks = nbt.List{}
+	}
+
+	if fr.PendingFluidTicks == nil {
+		fr.PendingFluidTicks = nbt.List{}
+	}
+
+	for i, s := range v.Palette {
+		fr.BlockStatePalette[i] = s.Tag()
+	}
+
+	positions := slices.SortedFunc(maps.Keys(v.BlockEntities), func(a, b [3]int) int {
+		return v.Index(a[0], a[1], a[2]) - v.Index(b[0], b[1], b[2])
+	})
+
+	for _, pos := range positions {
+		te := without(v.BlockEntities[pos])
+
+		for i, name := range [3]string{"x", "y", "z"} {
+			te[name] = &nbt.Tag{Type: nbt.TypeInt, Name: []byte(name), Value: int32(pos[i])}
+		}
+
+		fr.TileEntities = append(fr.TileEntities, te)
+	}
+
+	for _, e := range v.Entities {
+		pos := make(nbt.List, 3)
+
+		for i, p := range e.Pos {
+			pos[i] = &nbt.Tag{Type: nbt.TypeDouble, Value: p}
+		}
+
+		data := without(e.NBT)
+		data["Pos"] = &nbt.Tag{Type: nbt.TypeList, Name: []byte("Pos"), Value: pos}
+
+		fr.Entities = append(fr.Entities, data)
+	}
+
+	return fr
+}
+
+// updateMetadata recomputes the counts and the enclosing size from the regions.
+func (l *Litematic) updateMetadata() {
+	m := &l.Metadata
+
+	m.RegionCount = int32(len(l.Regions))
+	m.TotalBlocks, m.TotalVolume = 0, 0
+
+	var lo, hi [3]int
+
+	first := true
+
+	for _, r := range l.Regions {
+		rMin, rMax := r.Min(), r.Max()
+
+		for i := range 3 {
+			if first || rMin[i] < lo[i] {
+				lo[i] = rMin[i]
+			}
+
+			if first || rMax[i] > hi[i] {
+				hi[i] = rMax[i]
+			}
+		}
+
+		first = false
+
+		m.TotalVolume += int32(len(r.Volume.Indices))
+
+		for _, index := range r.Volume.Indices {
+			if !r.Volume.Palette[index].Equal(block.Air) {
+				m.TotalBlocks++
+			}
+		}
+	}
+
+	if !first {
+		m.EnclosingSize = Vec3{X: int32(hi[0] - lo[0] + 1), Y: int32(hi[1] - lo[1] + 1), Z: int32(hi[2] - lo[2] + 1)}
+	}
+}
+
+// Write writes l gzip compressed, like Litematica does. A zero Version is written as Version.
+func (l *Litematic) Write(w io.Writer) (err error) {
+	l.updateMetadata()
+
+	f := &file{
+		Version:     l.Version,
+		SubVersion:  l.SubVersion,
+		DataVersion: l.DataVersion,
+		Metadata:    l.Metadata,
+		Regions:     make(nbt.Compound, len(l.Regions)),
+	}
+
+	if f.Version == 0 {
+		f.Version = Version
+	}
+
+	for name, r := range l.Regions {
+		var tag *nbt.Tag
+
+		if tag, err = nbt.MarshalTag(r.file()); err != nil {
+			return
+		}
+
+		tag.Name = []byte(name)
+		f.Regions[name] = tag
+	}
+
+	root, err := nbt.MarshalTag(f)
+
+	if err != nil {
+		return
+	}
+
+	return nbt.MarshalWriter(w, root, nbt.WithCompression(nbt.CompressionGzip))
+}
@@ -0,0 +1,150 @@
+package litematic
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/nbt"
+	"github.com/nitwhiz/go-nbt/volume"
+)
+
+var (
+	stone = block.State{Name: "minecraft:stone"}
+	glass = block.State{Name: "minecraft:glass"}
+)
+
+func TestReadPacked(t *testing.T) {
+	// 2x1x2 region selected towards negative x, blocks air, stone, glass, stone packed with 2 bits
+	region := nbt.Compound{
+		"Position": {Type: nbt.TypeCompound, Name: []byte("Position"), Value: nbt.Compound{
+			"x": {Type: nbt.TypeInt, Name: []byte("x"), Value: int32(5)},
+			"y": {Type: nbt.TypeInt, Name: []byte("y"), Value: int32(0)},
+			"z": {Type: nbt.TypeInt, Name: []byte("z"), Value: int32(0)},
+		}},
+		"Size": {Type: nbt.TypeCompound, Name: []byte("Size"), Value: nbt.Compound{
+			"x": {Type: nbt.TypeInt, Name: []byte("x"), Value: int32(-2)},
+			"y": {Type: nbt.TypeInt, Name: []byte("y"), Value: int32(1)},
+			"z": {Type: nbt.TypeInt, Name: []byte("z"), Value: int32(2)},
+		}},
+		"BlockStatePalette": {Type: nbt.TypeList, Name: []byte("BlockStatePalette"), Value: nbt.List{
+			block.Air.Tag(), stone.Tag(), glass.Tag(),
+		}},
+		"BlockStates": {Type: nbt.TypeLongArray, Name: []byte("BlockStates"), Value: []int64{0b01_10_01_00}},
+	}
+
+	root := &nbt.Tag{Type: nbt.TypeCompound, Value: nbt.Compound{
+		"Version":              {Type: nbt.TypeInt, Name: []byte("Version"), Value: int32(6)},
+		"MinecraftDataVersion": {Type: nbt.TypeInt, Name: []byte("MinecraftDataVersion"), Value: int32(3953)},
+		"Metadata": {Type: nbt.TypeCompound, Name: []byte("Metadata"), Value: nbt.Compound{
+			"Author":      {Type: nbt.TypeString, Name: []byte("Author"), Value: "someone"},
+			"TimeCreated": {Type: nbt.TypeLong, Name: []byte("TimeCreated"), Value: int64(1700000000000)},
+		}},
+		"Regions": {Type: nbt.TypeCompound, Name: []byte("Regions"), Value: nbt.Compound{
+			"house": {Type: nbt.TypeCompound, Name: []byte("house"), Value: region},
+		}},
+	}}
+
+	bs, err := nbt.Marshal(root)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Read(bytes.NewReader(bs))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l.Metadata.Author != "someone" || !l.Metadata.Created().Equal(time.UnixMilli(1700000000000)) {
+		t.Fatalf("unexpected metadata %+v", l.Metadata)
+	}
+
+	r := l.Regions["house"]
+
+	if r == nil {
+		t.Fatalf("expected region house")
+	}
+
+	if r.Min() != [3]int{4, 0, 0} || r.Max() != [3]int{5, 0, 1} {
+		t.Fatalf("unexpected bounds %v to %v", r.Min(), r.Max())
+	}
+
+	expected := []block.State{block.Air, stone, glass, stone}
+
+	for i, s := range expected {
+		x, y, z := r.Volume.Pos(i)
+
+		if !r.At(x, y, z).Equal(s) {
+			t.Fatalf("block %d,%d,%d: expected %s, got %s", x, y, z, s, r.At(x, y, z))
+		}
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	v := volume.New(5, 5, 5)
+
+	for i := range 5 {
+		v.Set(i, i, i, stone)
+	}
+
+	for i := range 20 {
+		v.Set(i%5, 4, i/5, block.State{Name: "minecraft:wool", Properties: map[string]string{"n": string(rune('a' + i))}})
+	}
+
+	v.BlockEntities[[3]int{1, 1, 1}] = nbt.Compound{
+		"id": {Type: nbt.TypeString, Name: []byte("id"), Value: "minecraft:chest"},
+	}
+	v.Entities = append(v.Entities, volume.Entity{Pos: [3]float64{0.5, 1, 0.5}, NBT: nbt.Compound{
+		"id": {Type: nbt.TypeString, Name: []byte("id"), Value: "minecraft:pig"},
+	}})
+
+	l := &Litematic{
+		DataVersion: 3953,
+		Metadata:    Metadata{Name: "test", Author: "someone"},
+		Regions: map[string]*Region{
+			"a": NewRegion([3]int{0, 0, 0}, v),
+			"b": {Position: [3]int{9, 0, 0}, Size: [3]int{-2, 1, 1}, Volume: volume.New(2, 1, 1)},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+
+	if err := l.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Read(buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Version != Version || res.Metadata.RegionCount != 2 || res.Metadata.TotalVolume != 127 || res.Metadata.TotalBlocks != 25 {
+		t.Fatalf("unexpected version %d or metadata %+v", res.Version, res.Metadata)
+	}
+
+	if res.Metadata.EnclosingSize != (Vec3{X: 10, Y: 5, Z: 5}) {
+		t.Fatalf("unexpected enclosing size %+v", res.Metadata.EnclosingSize)
+	}
+
+	a := res.Regions["a"]
+
+	for i := range v.Indices {
+		x, y, z := v.Pos(i)
+
+		if !v.At(x, y, z).Equal(a.At(x, y, z)) {
+			t.Fatalf("block %d,%d,%d: expected %s, got %s", x, y, z, v.At(x, y, z), a.At(x, y, z))
+		}
+	}
+
+	if a.Volume.BlockEntities[[3]int{1, 1, 1}]["id"].Value != "minecraft:chest" || len(a.Volume.Entities) != 1 {
+		t.Fatalf("block entities or entities are missing")
+	}
+
+	if res.Regions["b"].Min() != [3]int{8, 0, 0} {
+		t.Fatalf("unexpected min corner %v", res.Regions["b"].Min())
+	}
+}
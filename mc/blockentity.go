@@ -0,0 +1,38 @@
+package mc
+
+import "github.com/nitwhiz/go-nbt/nbt"
+
+// BlockEntity holds the entries shared by all block entities. Types of specific block entities embed it.
+type BlockEntity struct {
+	ID         string       `nbt:"id"`
+	X          int32        `nbt:"x"`
+	Y          int32        `nbt:"y"`
+	Z          int32        `nbt:"z"`
+	KeepPacked bool         `nbt:"keepPacked,omitempty"`
+	Components nbt.Compound `nbt:"components,omitempty"`
+	Rest       nbt.Compound `nbt:",rest"`
+}
+
+// Container is a block entity with an inventory, like chests, barrels, hoppers and shulker boxes.
+type Container struct {
+	BlockEntity
+	Items         []InventoryItem `nbt:"Items"`
+	CustomName    string          `nbt:"CustomName,omitempty"`
+	LootTable     string          `nbt:"LootTable,omitempty"`
+	LootTableSeed int64           `nbt:"LootTableSeed,omitempty"`
+}
+
+// Sign is a sign or hanging sign, with the layout used since 1.20.
+type Sign struct {
+	BlockEntity
+	FrontText SignText `nbt:"front_text"`
+	BackText  SignText `nbt:"back_text"`
+	IsWaxed   bool     `nbt:"is_waxed"`
+}
+
+type SignText struct {
+	Messages       []string     `nbt:"messages"`
+	Color          string       `nbt:"color"`
+	HasGlowingText bool         `nbt:"has_glowing_text"`
+	Rest           nbt.Compound `nbt:",rest"`
+}
@@ -0,0 +1,8 @@
+// Package mc provides struct definitions for common Java Edition NBT formats: level.dat, players, entities, items
+// and block entities. They follow the layout of 1.21.4 (data version 4189), types marked as legacy describe older
+// layouts.
+//
+// The structs work with nbt.UnmarshalTag and nbt.Marshal. Entries without a field are collected in the Rest
+// compound of each struct and written back when marshalling, so files survive a rewrite even if the game added
+// entries not covered here. Fields tagged with omitempty are only written if they are set.
+package mc
@@ -0,0 +1,66 @@
+package mc
+
+import "github.com/nitwhiz/go-nbt/nbt"
+
+// Entity holds the entries shared by all entities. Types of specific entities embed it.
+type Entity struct {
+	ID                string       `nbt:"id,omitempty"`
+	UUID              nbt.IntArray `nbt:"UUID"`
+	Pos               []float64    `nbt:"Pos"`
+	Motion            []float64    `nbt:"Motion"`
+	Rotation          []float32    `nbt:"Rotation"`
+	FallDistance      float32      `nbt:"FallDistance"`
+	Fire              int16        `nbt:"Fire"`
+	Air               int16        `nbt:"Air"`
+	OnGround          bool         `nbt:"OnGround"`
+	Invulnerable      bool         `nbt:"Invulnerable"`
+	PortalCooldown    int32        `nbt:"PortalCooldown"`
+	CustomName        string       `nbt:"CustomName,omitempty"`
+	CustomNameVisible bool         `nbt:"CustomNameVisible,omitempty"`
+	Silent            bool         `nbt:"Silent,omitempty"`
+	NoGravity         bool         `nbt:"NoGravity,omitempty"`
+	Glowing           bool         `nbt:"Glowing,omitempty"`
+	TicksFrozen       int32        `nbt:"TicksFrozen,omitempty"`
+	Tags              []string     `nbt:"Tags,omitempty"`
+	Passengers        []Entity     `nbt:"Passengers,omitempty"`
+	Rest              nbt.Compound `nbt:",rest"`
+}
+
+// Mob holds the entries of living entities like animals, monsters and players.
+type Mob struct {
+	Entity
+	Health           float32     `nbt:"Health"`
+	AbsorptionAmount float32     `nbt:"AbsorptionAmount"`
+	HurtTime         int16       `nbt:"HurtTime"`
+	HurtByTimestamp  int32       `nbt:"HurtByTimestamp"`
+	DeathTime        int16       `nbt:"DeathTime"`
+	FallFlying       bool        `nbt:"FallFlying"`
+	Attributes       []Attribute `nbt:"attributes,omitempty"`
+	ArmorItems       []Item      `nbt:"ArmorItems,omitempty"`
+	HandItems        []Item      `nbt:"HandItems,omitempty"`
+}
+
+type Attribute struct {
+	ID        string              `nbt:"id"`
+	Base      float64             `nbt:"base"`
+	Modifiers []AttributeModifier `nbt:"modifiers,omitempty"`
+	Rest      nbt.Compound        `nbt:",rest"`
+}
+
+type AttributeModifier struct {
+	ID        string       `nbt:"id"`
+	Amount    float64      `nbt:"amount"`
+	Operation string       `nbt:"operation"`
+	Rest      nbt.Compound `nbt:",rest"`
+}
+
+// ItemEntity is a dropped item, entity id minecraft:item.
+type ItemEntity struct {
+	Entity
+	Item        Item         `nbt:"Item"`
+	Age         int16        `nbt:"Age"`
+	Health      int16        `nbt:"Health"`
+	PickupDelay int16        `nbt:"PickupDelay"`
+	Owner       nbt.IntArray `nbt:"Owner,omitempty"`
+	Thrower     nbt.IntArray `nbt:"Thrower,omitempty"`
+}
@@ -0,0 +1,26 @@
+package mc
+
+import (
+	"io"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+// Read decodes the root compound of an NBT file into v, for example a *LevelDat or a *Player.
+func Read(r io.Reader, v any) (err error) {
+	tag := &nbt.Tag{}
+
+	if err = nbt.NewDecoder(r).Decode(tag); err != nil {
+		return
+	}
+
+	return nbt.UnmarshalTag(v, tag)
+}
+
+// Write encodes v as the unnamed, gzip compressed root compound of an NBT file, like the game writes level.dat and
+// player files.
+func Write(w io.Writer, v any) error {
+	return nbt.MarshalWriter(w, &struct {
+		Root any `nbt:""`
+	}{v}, nbt.WithCompression(nbt.CompressionGzip))
+}
@@ -0,0 +1,31 @@
+package mc
+
+import "github.com/nitwhiz/go-nbt/nbt"
+
+// Item is an item stack as stored since 1.20.5. Empty slots of mob equipment are stored as empty compounds.
+type Item struct {
+	ID         string       `nbt:"id,omitempty"`
+	Count      int32        `nbt:"count,omitempty"`
+	Components nbt.Compound `nbt:"components,omitempty"`
+	Rest       nbt.Compound `nbt:",rest"`
+}
+
+// InventoryItem is an Item in a slot of an inventory or container.
+type InventoryItem struct {
+	Item
+	Slot int8 `nbt:"Slot"`
+}
+
+// LegacyItem is an item stack as stored from 1.13 up to 1.20.4, with its data in the tag compound.
+type LegacyItem struct {
+	ID    string       `nbt:"id,omitempty"`
+	Count int8         `nbt:"Count,omitempty"`
+	Tag   nbt.Compound `nbt:"tag,omitempty"`
+	Rest  nbt.Compound `nbt:",rest"`
+}
+
+// LegacyInventoryItem is a LegacyItem in a slot of an inventory or container.
+type LegacyInventoryItem struct {
+	LegacyItem
+	Slot int8 `nbt:"Slot"`
+}
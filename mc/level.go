@@ -0,0 +1,67 @@
+package mc
+
+import "github.com/nitwhiz/go-nbt/nbt"
+
+// LevelDat is the root compound of level.dat.
+type LevelDat struct {
+	Data Level        `nbt:"Data"`
+	Rest nbt.Compound `nbt:",rest"`
+}
+
+// Level is the Data compound of level.dat.
+type Level struct {
+	DataVersion      int32            `nbt:"DataVersion"`
+	FormatVersion    int32            `nbt:"version"`
+	Version          LevelVersion     `nbt:"Version"`
+	LevelName        string           `nbt:"LevelName"`
+	GameType         int32            `nbt:"GameType"`
+	Difficulty       int8             `nbt:"Difficulty"`
+	DifficultyLocked bool             `nbt:"DifficultyLocked"`
+	Hardcore         bool             `nbt:"hardcore"`
+	AllowCommands    bool             `nbt:"allowCommands"`
+	Initialized      bool             `nbt:"initialized"`
+	SpawnX           int32            `nbt:"SpawnX"`
+	SpawnY           int32            `nbt:"SpawnY"`
+	SpawnZ           int32            `nbt:"SpawnZ"`
+	SpawnAngle       float32          `nbt:"SpawnAngle"`
+	Time             int64            `nbt:"Time"`
+	DayTime          int64            `nbt:"DayTime"`
+	LastPlayed       int64            `nbt:"LastPlayed"`
+	Raining          bool             `nbt:"raining"`
+	RainTime         int32            `nbt:"rainTime"`
+	Thundering       bool             `nbt:"thundering"`
+	ThunderTime      int32            `nbt:"thunderTime"`
+	ClearWeatherTime int32            `nbt:"clearWeatherTime"`
+	GameRules        nbt.Compound     `nbt:"GameRules"`
+	DataPacks        DataPacks        `nbt:"DataPacks,omitempty"`
+	ServerBrands     []string         `nbt:"ServerBrands,omitempty"`
+	WasModded        bool             `nbt:"WasModded"`
+	WorldGenSettings WorldGenSettings `nbt:"WorldGenSettings,omitempty"`
+	// Player is the player of a singleplayer world.
+	Player Player       `nbt:"Player,omitempty"`
+	Rest   nbt.Compound `nbt:",rest"`
+}
+
+// LevelVersion describes the game version that saved the world last.
+type LevelVersion struct {
+	ID       int32        `nbt:"Id"`
+	Name     string       `nbt:"Name"`
+	Series   string       `nbt:"Series"`
+	Snapshot bool         `nbt:"Snapshot"`
+	Rest     nbt.Compound `nbt:",rest"`
+}
+
+type DataPacks struct {
+	Enabled  []string     `nbt:"Enabled"`
+	Disabled []string     `nbt:"Disabled"`
+	Rest     nbt.Compound `nbt:",rest"`
+}
+
+// WorldGenSettings holds the seed and the dimension generators, it's stored in level.dat since 1.16.
+type WorldGenSettings struct {
+	Seed             int64        `nbt:"seed"`
+	GenerateFeatures bool         `nbt:"generate_features"`
+	BonusChest       bool         `nbt:"bonus_chest"`
+	Dimensions       nbt.Compound `nbt:"dimensions"`
+	Rest             nbt.Compound `nbt:",rest"`
+}
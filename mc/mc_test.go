@@ -0,0 +1,135 @@
+package mc
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+func readTag(t *testing.T, bs []byte) *nbt.Tag {
+	t.Helper()
+
+	tag := &nbt.Tag{}
+
+	if err := nbt.Unmarshal(bs, tag); err != nil {
+		t.Fatal(err)
+	}
+
+	return tag
+}
+
+func TestLevelDatRoundTrip(t *testing.T) {
+	bs, err := os.ReadFile("../testdata/level.dat")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ld := LevelDat{}
+
+	if err := Read(bytes.NewReader(bs), &ld); err != nil {
+		t.Fatal(err)
+	}
+
+	if ld.Data.LevelName != "test-01" || ld.Data.Version.Name != "1.21.4" || ld.Data.DataVersion != DataVersion1_21_4 {
+		t.Fatalf("unexpected level %s, version %s (%d)", ld.Data.LevelName, ld.Data.Version.Name, ld.Data.DataVersion)
+	}
+
+	if ld.Data.WorldGenSettings.Seed != -7399799678337074839 || !ld.Data.WorldGenSettings.GenerateFeatures {
+		t.Fatalf("unexpected world gen settings %+v", ld.Data.WorldGenSettings)
+	}
+
+	p := ld.Data.Player
+
+	if p.Health != 20 || !p.OnGround || len(p.Pos) != 3 || len(p.UUID) != 4 || !p.Abilities.MayBuild {
+		t.Fatalf("unexpected player %+v", p)
+	}
+
+	if len(p.Attributes) != 3 || p.Attributes[1].ID != "minecraft:block_interaction_range" {
+		t.Fatalf("unexpected attributes %+v", p.Attributes)
+	}
+
+	if _, ok := p.Rest["recipeBook"]; !ok {
+		t.Fatalf("expected recipeBook in the player's rest, got %v", p.Rest)
+	}
+
+	if _, ok := ld.Data.Rest["DragonFight"]; !ok {
+		t.Fatalf("expected DragonFight in the level's rest")
+	}
+
+	ld.Data.LevelName = "renamed"
+
+	buf := new(bytes.Buffer)
+
+	if err := Write(buf, &ld); err != nil {
+		t.Fatal(err)
+	}
+
+	original := readTag(t, bs)
+	rewritten := readTag(t, buf.Bytes())
+
+	if data, _ := rewritten.Find("Data"); data == nil {
+		t.Fatalf("expected Data in the root compound")
+	}
+
+	levelName, _ := original.Value.(nbt.Compound)["Data"].Find("LevelName")
+	levelName.Value = "renamed"
+
+	expected, err := original.SNBT()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := rewritten.SNBT()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s != expected {
+		t.Fatalf("level.dat differs after rewrite:\n%s\n%s", expected, s)
+	}
+}
+
+func TestContainerRoundTrip(t *testing.T) {
+	chest := Container{
+		BlockEntity: BlockEntity{ID: "minecraft:chest", X: 1, Y: 64, Z: -3},
+		Items: []InventoryItem{
+			{Item: Item{ID: "minecraft:diamond", Count: 3}, Slot: 0},
+			{Item: Item{ID: "minecraft:stick", Count: 1, Components: nbt.Compound{
+				"minecraft:custom_name": {Type: nbt.TypeString, Name: []byte("minecraft:custom_name"), Value: `"Wand"`},
+			}}, Slot: 26},
+		},
+	}
+
+	bs, err := nbt.Marshal(&struct {
+		Root Container `nbt:""`
+	}{chest})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag := readTag(t, bs)
+
+	if _, ok := tag.Find("CustomName"); ok {
+		t.Fatalf("expected empty CustomName to be left out")
+	}
+
+	res := Container{}
+
+	if err := nbt.UnmarshalTag(&res, tag); err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Y != 64 || len(res.Items) != 2 || res.Items[1].Slot != 26 || res.Items[1].Components["minecraft:custom_name"] == nil {
+		t.Fatalf("unexpected container %+v", res)
+	}
+
+	if len(res.Rest) != 0 || len(res.Items[0].Rest) != 0 {
+		t.Fatalf("expected no unknown entries, got %v and %v", res.Rest, res.Items[0].Rest)
+	}
+}
@@ -0,0 +1,37 @@
+package mc
+
+import "github.com/nitwhiz/go-nbt/nbt"
+
+// Player is the root compound of a file in playerdata and the Player compound of singleplayer worlds.
+type Player struct {
+	Mob
+	DataVersion         int32           `nbt:"DataVersion"`
+	Dimension           string          `nbt:"Dimension"`
+	PlayerGameType      int32           `nbt:"playerGameType"`
+	Inventory           []InventoryItem `nbt:"Inventory"`
+	EnderItems          []InventoryItem `nbt:"EnderItems"`
+	SelectedItemSlot    int32           `nbt:"SelectedItemSlot"`
+	Score               int32           `nbt:"Score"`
+	XpLevel             int32           `nbt:"XpLevel"`
+	XpP                 float32         `nbt:"XpP"`
+	XpTotal             int32           `nbt:"XpTotal"`
+	XpSeed              int32           `nbt:"XpSeed"`
+	FoodLevel           int32           `nbt:"foodLevel"`
+	FoodSaturationLevel float32         `nbt:"foodSaturationLevel"`
+	FoodExhaustionLevel float32         `nbt:"foodExhaustionLevel"`
+	FoodTickTimer       int32           `nbt:"foodTickTimer"`
+	SleepTimer          int16           `nbt:"SleepTimer"`
+	SeenCredits         bool            `nbt:"seenCredits"`
+	Abilities           Abilities       `nbt:"abilities"`
+}
+
+type Abilities struct {
+	Flying       bool         `nbt:"flying"`
+	FlySpeed     float32      `nbt:"flySpeed"`
+	WalkSpeed    float32      `nbt:"walkSpeed"`
+	Instabuild   bool         `nbt:"instabuild"`
+	Invulnerable bool         `nbt:"invulnerable"`
+	MayBuild     bool         `nbt:"mayBuild"`
+	MayFly       bool         `nbt:"mayfly"`
+	Rest         nbt.Compound `nbt:",rest"`
+}
@@ -0,0 +1,11 @@
+package mc
+
+// Data versions of releases that changed the formats in this package.
+const (
+	DataVersion1_13   = 1519 // flattening, block states replace numeric ids
+	DataVersion1_16   = 2566 // packed long arrays stop spanning longs
+	DataVersion1_18   = 2860 // sections move out of the Level compound
+	DataVersion1_20_5 = 3837 // item components replace the tag compound
+	DataVersion1_21   = 3953
+	DataVersion1_21_4 = 4189
+)
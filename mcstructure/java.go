@@ -0,0 +1,110 @@
+package mcstructure
+
+import (
+	"maps"
+
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/nbt"
+	"github.com/nitwhiz/go-nbt/structure"
+	"github.com/nitwhiz/go-nbt/volume"
+)
+
+// Water is the state put into the second layer of waterlogged blocks.
+var Water = BlockState{
+	Name: "minecraft:water",
+	States: nbt.Compound{
+		"liquid_depth": {Type: nbt.TypeInt, Name: []byte("liquid_depth"), Value: int32(0)},
+	},
+}
+
+// ToJava converts s into a Java Edition structure, mapping translates the Bedrock block states. Water in the
+// second layer sets waterlogged=true on blocks having that property. Block entities and entities are left out,
+// their data differs between the editions.
+func (s *Structure) ToJava(dataVersion int32, mapping func(BlockState) (block.State, error)) (_ *structure.Structure, err error) {
+	states := make([]block.State, len(s.Palette))
+
+	for i, state := range s.Palette {
+		if states[i], err = mapping(state); err != nil {
+			return
+		}
+	}
+
+	v := volume.New(s.Size[0], s.Size[1], s.Size[2])
+
+	v.Palette[0] = structure.Void
+
+	for i, index := range s.Layers[0] {
+		if index == Void {
+			continue
+		}
+
+		state := states[index]
+
+		if second := s.Layers[1][i]; second != Void && s.Palette[second].Name == Water.Name {
+			if _, ok := state.Properties["waterlogged"]; ok {
+				state.Properties = maps.Clone(state.Properties)
+				state.Properties["waterlogged"] = "true"
+			}
+		}
+
+		x, y, z := s.Pos(i)
+
+		v.Set(x, y, z, state)
+	}
+
+	return structure.FromVolume(v, dataVersion), nil
+}
+
+// FromJava converts the Java Edition structure j with the states of the given palette into a Bedrock structure,
+// mapping translates the Java block states. Waterlogged blocks get water in the second layer. Block entities and
+// entities are left out, their data differs between the editions.
+func FromJava(j *structure.Structure, palette int, mapping func(block.State) (BlockState, error)) (s *Structure, err error) {
+	v := j.Volume(palette)
+
+	s = New(v.Size[0], v.Size[1], v.Size[2])
+
+	indices := make([]int32, len(v.Palette))
+	water := int32(Void)
+
+	for i, state := range v.Palette {
+		indices[i] = Void
+
+		if state.Equal(structure.Void) {
+			continue
+		}
+
+		var mapped BlockState
+
+		if mapped, err = mapping(state); err != nil {
+			return nil, err
+		}
+
+		indices[i] = int32(len(s.Palette))
+		s.Palette = append(s.Palette, mapped)
+	}
+
+	for i, index := range v.Indices {
+		if indices[index] == Void {
+			continue
+		}
+
+		x, y, z := v.Pos(i)
+		bi := s.Index(x, y, z)
+
+		s.Layers[0][bi] = indices[index]
+
+		if v.Palette[index].Properties["waterlogged"] == "true" {
+			if water == Void {
+				w := Water
+				w.Version = s.Palette[indices[index]].Version
+
+				water = int32(len(s.Palette))
+				s.Palette = append(s.Palette, w)
+			}
+
+			s.Layers[1][bi] = water
+		}
+	}
+
+	return
+}
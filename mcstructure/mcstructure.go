@@ -0,0 +1,232 @@
+package mcstructure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+// FormatVersion is the only format version of .mcstructure files.
+const FormatVersion = 1
+
+// Void is the palette index of positions left untouched when the structure is placed.
+const Void = -1
+
+// Structure is a Bedrock Edition .mcstructure file.
+type Structure struct {
+	FormatVersion int32
+	Size          [3]int
+	// Origin is the world position the structure was saved from.
+	Origin  [3]int
+	Palette []BlockState
+	// Layers holds palette indices, ordered by z first, then y, then x. The first layer holds the blocks, the
+	// second one the blocks in the same position, usually water in waterlogged blocks.
+	Layers [2][]int32
+	// BlockPositionData holds extra data by block index, like block_entity_data.
+	BlockPositionData map[int]nbt.Compound
+	Entities          []nbt.Compound
+}
+
+// BlockState is a Bedrock block state. Unlike Java Edition, state values are typed: bytes, ints or strings.
+type BlockState struct {
+	Name    string       `nbt:"name"`
+	States  nbt.Compound `nbt:"states"`
+	Version int32        `nbt:"version"`
+	Rest    nbt.Compound `nbt:",rest"`
+}
+
+// file is the NBT layout of a .mcstructure file.
+type file struct {
+	FormatVersion int32         `nbt:"format_version"`
+	Size          []int32       `nbt:"size"`
+	Origin        []int32       `nbt:"structure_world_origin"`
+	Structure     fileStructure `nbt:"structure"`
+}
+
+type fileStructure struct {
+	BlockIndices [][]int32      `nbt:"block_indices"`
+	Entities     []nbt.Compound `nbt:"entities"`
+	Palette      struct {
+		Default filePalette `nbt:"default"`
+	} `nbt:"palette"`
+}
+
+type filePalette struct {
+	BlockPalette      []BlockState `nbt:"block_palette"`
+	BlockPositionData nbt.Compound `nbt:"block_position_data"`
+}
+
+// New returns a structure of the given size with all positions void.
+func New(sizeX, sizeY, sizeZ int) *Structure {
+	s := &Structure{
+		FormatVersion:     FormatVersion,
+		Size:              [3]int{sizeX, sizeY, sizeZ},
+		BlockPositionData: map[int]nbt.Compound{},
+	}
+
+	for i := range s.Layers {
+		s.Layers[i] = slices.Repeat([]int32{Void}, sizeX*sizeY*sizeZ)
+	}
+
+	return s
+}
+
+// Index returns the position of x, y, z in the layers.
+func (s *Structure) Index(x, y, z int) int {
+	return (x*s.Size[1]+y)*s.Size[2] + z
+}
+
+// Pos returns the position of the block at i in the layers.
+func (s *Structure) Pos(i int) (x, y, z int) {
+	return i / (s.Size[1] * s.Size[2]), i / s.Size[2] % s.Size[1], i % s.Size[2]
+}
+
+// At returns the block state of layer at x, y, z. ok is false for void positions.
+func (s *Structure) At(layer int, x, y, z int) (state BlockState, ok bool) {
+	index := s.Layers[layer][s.Index(x, y, z)]
+
+	if index == Void {
+		return
+	}
+
+	return s.Palette[index], true
+}
+
+func vec3(v []int32, what string) (res [3]int, err error) {
+	if len(v) != 3 {
+		return res, fmt.Errorf("mcstructure: %s has %d components, expected 3", what, len(v))
+	}
+
+	for i, n := range v {
+		res[i] = int(n)
+	}
+
+	return
+}
+
+// Read reads a .mcstructure file, which is uncompressed little endian NBT.
+func Read(r io.Reader) (s *Structure, err error) {
+	d := nbt.NewDecoder(r)
+
+	d.SetDialect(nbt.DialectBedrock)
+
+	root := &nbt.Tag{}
+
+	if err = d.Decode(root); err != nil {
+		return
+	}
+
+	f := file{}
+
+	if err = nbt.UnmarshalTag(&f, root); err != nil {
+		return
+	}
+
+	s = &Structure{FormatVersion: f.FormatVersion, BlockPositionData: map[int]nbt.Compound{}}
+
+	if s.Size, err = vec3(f.Size, "size"); err != nil {
+		return nil, err
+	}
+
+	if s.Origin, err = vec3(f.Origin, "structure_world_origin"); err != nil {
+		return nil, err
+	}
+
+	st := &f.Structure
+	volume := s.Size[0] * s.Size[1] * s.Size[2]
+
+	if len(st.BlockIndices) != 2 {
+		return nil, fmt.Errorf("mcstructure: expected 2 block layers, got %d", len(st.BlockIndices))
+	}
+
+	s.Palette = st.Palette.Default.BlockPalette
+
+	for layer, indices := range st.BlockIndices {
+		if len(indices) != volume {
+			return nil, fmt.Errorf("mcstructure: layer %d has %d blocks, expected %d", layer, len(indices), volume)
+		}
+
+		for _, index := range indices {
+			if index < Void || int(index) >= len(s.Palette) {
+				return nil, fmt.Errorf("mcstructure: invalid palette index %d in layer %d", index, layer)
+			}
+		}
+
+		s.Layers[layer] = indices
+	}
+
+	for key, tag := range st.Palette.Default.BlockPositionData {
+		i, err := strconv.Atoi(key)
+
+		if err != nil || i < 0 || i >= volume {
+			return nil, fmt.Errorf("mcstructure: invalid block position data index %q", key)
+		}
+
+		c, ok := tag.Value.(nbt.Compound)
+
+		if !ok {
+			return nil, errors.New("mcstructure: block position data is not a compound")
+		}
+
+		s.BlockPositionData[i] = c
+	}
+
+	s.Entities = st.Entities
+
+	return
+}
+
+func int32s(v [3]int) []int32 {
+	return []int32{int32(v[0]), int32(v[1]), int32(v[2])}
+}
+
+// Write writes s as uncompressed little endian NBT.
+func (s *Structure) Write(w io.Writer) (err error) {
+	f := file{
+		FormatVersion: s.FormatVersion,
+		Size:          int32s(s.Size),
+		Origin:        int32s(s.Origin),
+	}
+
+	if f.FormatVersion == 0 {
+		f.FormatVersion = FormatVersion
+	}
+
+	st := &f.Structure
+
+	st.BlockIndices = [][]int32{s.Layers[0], s.Layers[1]}
+
+	st.Entities = s.Entities
+
+	if st.Entities == nil {
+		st.Entities = []nbt.Compound{}
+	}
+
+	st.Palette.Default.BlockPalette = slices.Clone(s.Palette)
+
+	for i, state := range st.Palette.Default.BlockPalette {
+		if state.States == nil {
+			st.Palette.Default.BlockPalette[i].States = nbt.Compound{}
+		}
+	}
+
+	st.Palette.Default.BlockPositionData = make(nbt.Compound, len(s.BlockPositionData))
+
+	for i, c := range s.BlockPositionData {
+		key := strconv.Itoa(i)
+
+		st.Palette.Default.BlockPositionData[key] = &nbt.Tag{Type: nbt.TypeCompound, Name: []byte(key), Value: c}
+	}
+
+	root, err := nbt.MarshalTag(&f)
+
+	if err != nil {
+		return
+	}
+
+	return nbt.MarshalWriter(w, root, nbt.WithDialect(nbt.DialectBedrock))
+}
@@ -0,0 +1,144 @@
+package mcstructure
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+var stairs = BlockState{
+	Name: "minecraft:oak_stairs",
+	States: nbt.Compound{
+		"upside_down_bit":  {Type: nbt.TypeByte, Name: []byte("upside_down_bit"), Value: int8(0)},
+		"weirdo_direction": {Type: nbt.TypeInt, Name: []byte("weirdo_direction"), Value: int32(1)},
+	},
+	Version: 18090528,
+}
+
+func testStructure() *Structure {
+	s := New(2, 3, 4)
+
+	s.Origin = [3]int{100, 64, -20}
+	s.Palette = []BlockState{{Name: "minecraft:stone", Version: 18090528}, stairs, Water}
+
+	s.Layers[0][s.Index(0, 0, 0)] = 0
+	s.Layers[0][s.Index(1, 2, 3)] = 1
+	s.Layers[1][s.Index(1, 2, 3)] = 2
+	s.Layers[0][s.Index(1, 0, 2)] = 0
+
+	s.BlockPositionData[s.Index(1, 0, 2)] = nbt.Compound{
+		"block_entity_data": {Type: nbt.TypeCompound, Name: []byte("block_entity_data"), Value: nbt.Compound{
+			"id": {Type: nbt.TypeString, Name: []byte("id"), Value: "Chest"},
+		}},
+	}
+
+	return s
+}
+
+func TestWriteRead(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	if err := testStructure().Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// little endian root: compound type, then a zero length name
+	if bs := buf.Bytes(); bs[0] != nbt.TypeCompound || bs[1] != 0 || bs[2] != 0 {
+		t.Fatalf("unexpected header %v", bs[:3])
+	}
+
+	root := &nbt.Tag{}
+
+	if err := nbt.Unmarshal(buf.Bytes(), root, nbt.WithDialect(nbt.DialectBedrock)); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, _ := root.Find("format_version"); v.Value != int32(1) {
+		t.Fatalf("expected format version 1, got %v", v.Value)
+	}
+
+	s, err := Read(buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Size != [3]int{2, 3, 4} || s.Origin != [3]int{100, 64, -20} {
+		t.Fatalf("unexpected size %v or origin %v", s.Size, s.Origin)
+	}
+
+	if state, ok := s.At(0, 1, 2, 3); !ok || state.Name != stairs.Name || state.States["weirdo_direction"].Value != int32(1) {
+		t.Fatalf("expected stairs, got %+v", state)
+	}
+
+	if _, ok := s.At(0, 1, 1, 1); ok {
+		t.Fatalf("expected void at 1,1,1")
+	}
+
+	if s.BlockPositionData[s.Index(1, 0, 2)] == nil {
+		t.Fatalf("expected block position data")
+	}
+
+	if x, y, z := s.Pos(s.Index(1, 2, 3)); x != 1 || y != 2 || z != 3 {
+		t.Fatalf("expected 1,2,3, got %d,%d,%d", x, y, z)
+	}
+}
+
+func TestJavaConversion(t *testing.T) {
+	toJava := func(s BlockState) (block.State, error) {
+		switch s.Name {
+		case "minecraft:stone":
+			return block.State{Name: "minecraft:stone"}, nil
+		case "minecraft:oak_stairs":
+			return block.State{Name: "minecraft:oak_stairs", Properties: map[string]string{"facing": "west", "waterlogged": "false"}}, nil
+		case "minecraft:water":
+			return block.State{Name: "minecraft:water", Properties: map[string]string{"level": "0"}}, nil
+		}
+
+		return block.State{}, fmt.Errorf("unknown block %s", s.Name)
+	}
+
+	j, err := testStructure().ToJava(3953, toJava)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(j.Blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(j.Blocks))
+	}
+
+	if p, ok := j.At(1, 2, 3); !ok || p.State.Properties["waterlogged"] != "true" {
+		t.Fatalf("expected waterlogged stairs, got %v", p.State)
+	}
+
+	fromJava := func(s block.State) (BlockState, error) {
+		if strings.HasSuffix(s.Name, "stairs") {
+			return stairs, nil
+		}
+
+		return BlockState{Name: s.Name, Version: 18090528}, nil
+	}
+
+	s, err := FromJava(j, 0, fromJava)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state, ok := s.At(1, 1, 2, 3); !ok || state.Name != Water.Name {
+		t.Fatalf("expected water in the second layer, got %+v", state)
+	}
+
+	if _, ok := s.At(0, 0, 1, 0); ok {
+		t.Fatalf("expected void at 0,1,0")
+	}
+
+	if state, ok := s.At(0, 1, 0, 2); !ok || state.Name != "minecraft:stone" {
+		t.Fatalf("expected stone, got %+v", state)
+	}
+}
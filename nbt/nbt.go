@@ -2,7 +2,6 @@ package nbt
 
 import (
 	"bytes"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -36,18 +35,6 @@ type Tag struct {
 	Value any
 }
 
-func (t *Tag) MarshalJSON() (bs []byte, err error) {
-	return json.Marshal(struct {
-		Type  int    `json:"type"`
-		Name  string `json:"name"`
-		Value any    `json:"value"`
-	}{
-		Type:  t.Type,
-		Name:  string(t.Name),
-		Value: t.Value,
-	})
-}
-
 func (t *Tag) String() string {
 	return tagAsString(t, false, 0)
 }
@@ -78,45 +65,46 @@ func (t *Tag) Find(name string) (tag *Tag, ok bool) {
 	}
 }
 
-func tagAsString(t *Tag, skipName bool, depth int) string {
-	var tagTypeName string
-
-	switch t.Type {
+// typeName returns the name of a tag type as used in the NBT specification, without the TAG_ prefix.
+func typeName(typ int) string {
+	switch typ {
 	case TypeByte:
-		tagTypeName = "Byte"
+		return "Byte"
 	case TypeShort:
-		tagTypeName = "Short"
+		return "Short"
 	case TypeInt:
-		tagTypeName = "Int"
+		return "Int"
 	case TypeLong:
-		tagTypeName = "Long"
+		return "Long"
 	case TypeFloat:
-		tagTypeName = "Float"
+		return "Float"
 	case TypeDouble:
-		tagTypeName = "Double"
+		return "Double"
 	case TypeByteArray:
-		tagTypeName = "Byte_Array"
+		return "Byte_Array"
 	case TypeString:
-		tagTypeName = "String"
+		return "String"
 	case TypeList:
-		tagTypeName = "List"
+		return "List"
 	case TypeCompound:
-		tagTypeName = "Compound"
+		return "Compound"
 	case TypeIntArray:
-		tagTypeName = "Int_Array"
+		return "Int_Array"
 	case TypeLongArray:
-		tagTypeName = "Long_Array"
+		return "Long_Array"
 	default:
-		tagTypeName = "Unknown"
+		return "Unknown"
 	}
+}
 
+func tagAsString(t *Tag, skipName bool, depth int) string {
 	displayName := "None"
 
 	if !skipName {
 		displayName = "'" + string(t.Name) + "'"
 	}
 
-	res := fmt.Sprintf("TAG_%s(%s): ", tagTypeName, displayName)
+	res := fmt.Sprintf("TAG_%s(%s): ", typeName(t.Type), displayName)
 
 	prefix := strings.Repeat("  ", depth)
 
@@ -147,86 +135,95 @@ func tagAsString(t *Tag, skipName bool, depth int) string {
 	return prefix + res + "\n"
 }
 
+// UnmarshalTag stores tag in the value v points to. Numbers are converted to wider types, e.g. a TAG_Short into an
+// int32 field, and TAG_Byte values into bools. Tags that can't be stored are reported as *UnmarshalTypeError.
 func UnmarshalTag(v any, tag *Tag) (err error) {
-	typ := reflect.TypeOf(v)
 	val := reflect.ValueOf(v)
 
-	if typ.Kind() == reflect.Ptr {
-		typ = typ.Elem()
-		val = val.Elem()
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return fmt.Errorf("nbt: UnmarshalTag needs a non-nil pointer, got %T", v)
 	}
 
-	if typ.Kind() == reflect.Struct {
-		for i := 0; i < typ.NumField(); i++ {
-			field := typ.Field(i)
-
-			nbtTagName, ok := field.Tag.Lookup("nbt")
-
-			if ok {
-				foundTag, ok := tag.Find(nbtTagName)
-
-				if ok {
-					if field.Type.Kind() == reflect.Struct {
-						if err = UnmarshalTag(val.Field(i).Addr().Interface(), foundTag); err != nil {
-							return
-						}
-					} else if field.Type.Kind() == reflect.Slice {
-						switch listValues := foundTag.Value.(type) {
-						case List:
-							s := reflect.MakeSlice(reflect.SliceOf(field.Type.Elem()), len(listValues), len(listValues))
-
-							for i := 0; i < len(listValues); i++ {
-								if err = UnmarshalTag(s.Index(i).Addr().Interface(), listValues[i]); err != nil {
-									return
-								}
-							}
-
-							val.Field(i).Set(s)
-						case []byte:
-							val.Field(i).Set(reflect.ValueOf(listValues))
-						case []int32:
-							val.Field(i).Set(reflect.ValueOf(listValues))
-						case []int64:
-							val.Field(i).Set(reflect.ValueOf(listValues))
-						}
-					} else {
-						val.Field(i).Set(reflect.ValueOf(foundTag.Value))
-					}
-				}
-			}
-		}
-	} else {
-		_, ok := val.Interface().(*Tag)
+	return unmarshalValue(val.Elem(), tag, "")
+}
 
-		if ok {
-			val.Set(reflect.ValueOf(tag))
-		} else {
-			val.Set(reflect.ValueOf(tag.Value))
-		}
-	}
+// MarshalTag converts v into an unnamed tag, the counterpart of UnmarshalTag. Unlike Marshal, structs with a single
+// field are not unwrapped.
+func MarshalTag(v any) (tag *Tag, err error) {
+	tag = &Tag{}
 
-	if u, ok := v.(Unmarshaler); ok {
-		if err = u.UnmarshalTag(tag); err != nil {
-			return
-		}
+	if err = marshalValue(tag, v, false); err != nil {
+		return nil, err
 	}
 
 	return
 }
 
 func marshalValue(dstTag *Tag, v any, root bool) (err error) {
+	switch t := v.(type) {
+	case *Tag:
+		if root {
+			dstTag.Name = t.Name
+		}
+
+		dstTag.Type = t.Type
+		dstTag.Value = t.Value
+
+		return
+	case Compound:
+		dstTag.Type = TypeCompound
+		dstTag.Value = t
+
+		return
+	}
+
 	val := reflect.ValueOf(v)
-	typ := reflect.TypeOf(v)
 
-	if val.Type().Kind() == reflect.Ptr {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return errors.New("nbt: cannot marshal nil " + val.Type().String())
+		}
+
 		val = val.Elem()
-		typ = typ.Elem()
+	}
+
+	if !val.IsValid() {
+		return errors.New("nbt: cannot marshal nil")
+	}
+
+	typ := val.Type()
+
+	switch typ {
+	case tagType:
+		t := val.Interface().(Tag)
+
+		return marshalValue(dstTag, &t, root)
+	case reflect.TypeOf(IntArray{}):
+		dstTag.Type = TypeIntArray
+		dstTag.Value = []int32(val.Interface().(IntArray))
+
+		return
+	case reflect.TypeOf(LongArray{}):
+		dstTag.Type = TypeLongArray
+		dstTag.Value = []int64(val.Interface().(LongArray))
+
+		return
 	}
 
 	switch val.Kind() {
+	case reflect.Bool:
+		dstTag.Type = TypeByte
+		dstTag.Value = int8(0)
+
+		if val.Bool() {
+			dstTag.Value = int8(1)
+		}
+	case reflect.Int8:
+		dstTag.Type = TypeByte
+		dstTag.Value = int8(val.Int())
 	case reflect.Uint8:
 		dstTag.Type = TypeByte
-		dstTag.Value = byte(val.Int())
+		dstTag.Value = int8(val.Uint())
 	case reflect.Int16:
 		dstTag.Type = TypeShort
 		dstTag.Value = int16(val.Int())
@@ -245,13 +242,17 @@ func marshalValue(dstTag *Tag, v any, root bool) (err error) {
 	case reflect.String:
 		dstTag.Type = TypeString
 		dstTag.Value = val.String()
-	case reflect.Slice:
+	case reflect.Slice, reflect.Array:
 		switch typ.Elem().Kind() {
 		case reflect.Uint8:
+			bs := make([]byte, val.Len())
+
+			reflect.Copy(reflect.ValueOf(bs), val)
+
 			dstTag.Type = TypeByteArray
-			dstTag.Value = val.Bytes()
+			dstTag.Value = bs
 		default:
-			// todo: Int_Array and Long_Array will be marshalled as lists. This might not be what we want.
+			// []int32 and []int64 are marshalled as lists, use IntArray and LongArray for arrays
 			values := make(List, 0, val.Len())
 
 			for i := 0; i < val.Len(); i++ {
@@ -261,50 +262,82 @@ func marshalValue(dstTag *Tag, v any, root bool) (err error) {
 					return
 				}
 
+				// all items of a list share the item type written in front of them
+				if i > 0 && itemTag.Type != values[0].Type {
+					return fmt.Errorf("nbt: list item %d is TAG_%s, expected TAG_%s", i, typeName(itemTag.Type), typeName(values[0].Type))
+				}
+
 				values = append(values, itemTag)
 			}
 
 			dstTag.Type = TypeList
 			dstTag.Value = values
 		}
-	case reflect.Struct:
-		c := Compound{}
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			return errors.New("nbt: unsupported map key type: " + typ.Key().String())
+		}
 
-		for i := 0; i < val.NumField(); i++ {
-			nbtTagName, hasNbtTag := typ.Field(i).Tag.Lookup("nbt")
+		c := make(Compound, val.Len())
 
-			if hasNbtTag {
-				nbtTag := &Tag{
-					Name: []byte(nbtTagName),
-				}
+		for it := val.MapRange(); it.Next(); {
+			// NBT has no null, nil entries are left out
+			if isNilValue(it.Value()) {
+				continue
+			}
 
-				if err = marshalValue(nbtTag, val.Field(i).Interface(), false); err != nil {
-					return
-				}
+			name := it.Key().String()
+			child := &Tag{Name: []byte(name)}
 
-				c[nbtTagName] = nbtTag
+			if err = marshalValue(child, it.Value().Interface(), false); err != nil {
+				return
 			}
+
+			c[name] = child
 		}
 
-		if len(c) > 0 {
-			if root && len(c) == 1 {
-				// unwrap if there's only one child tag in root
+		dstTag.Type = TypeCompound
+		dstTag.Value = c
+	case reflect.Struct:
+		c := Compound{}
+		fields := fieldsOf(typ)
 
-				var firstTag *Tag
+		for _, f := range fields.fields {
+			fieldVal := val.FieldByIndex(f.index)
 
-				for _, t := range c {
-					firstTag = t
-					break
-				}
+			if (f.omitEmpty && isEmptyValue(fieldVal)) || isNilValue(fieldVal) {
+				continue
+			}
+
+			nbtTag := &Tag{
+				Name: []byte(f.name),
+			}
 
-				if firstTag != nil {
-					*dstTag = *firstTag
+			if err = marshalValue(nbtTag, fieldVal.Interface(), false); err != nil {
+				return
+			}
+
+			c[f.name] = nbtTag
+		}
+
+		if fields.rest != nil {
+			for name, child := range val.FieldByIndex(fields.rest).Interface().(Compound) {
+				if _, ok := c[name]; !ok && !fields.has(name) {
+					c[name] = child
 				}
-			} else {
-				dstTag.Type = TypeCompound
-				dstTag.Value = c
 			}
 		}
+
+		if root && len(c) == 1 {
+			// unwrap if there's only one child tag in root
+
+			for _, t := range c {
+				*dstTag = *t
+			}
+		} else {
+			dstTag.Type = TypeCompound
+			dstTag.Value = c
+		}
 	default:
 		err = errors.New("unsupported type: " + val.Kind().String())
 		return
@@ -313,20 +346,44 @@ func marshalValue(dstTag *Tag, v any, root bool) (err error) {
 	return
 }
 
-func Unmarshal(bs []byte, v any) error {
-	return UnmarshalReader(bytes.NewReader(bs), v)
+// isNilValue reports whether v is a nil pointer or interface, which have no NBT representation.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
 }
 
-func UnmarshalReader(r io.Reader, v any) error {
-	d := newDecoder(r)
+// isEmptyValue reports whether a field tagged with omitempty is left out.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
 
-	t, err := d.readNextTag(-1)
+func Unmarshal(bs []byte, v any, opts ...Option) error {
+	return UnmarshalReader(bytes.NewReader(bs), v, opts...)
+}
 
-	if err != nil {
-		return err
-	}
+func UnmarshalReader(r io.Reader, v any, opts ...Option) error {
+	d := NewDecoder(r)
+
+	d.apply(opts)
+
+	return d.Decode(v)
+}
+
+// unmarshalRoot stores the root tag t in v. A *Tag receives the root tag itself, everything else is unmarshalled
+// from a compound wrapping the root tag.
+func unmarshalRoot(v any, t *Tag) error {
+	if dst, ok := v.(*Tag); ok {
+		*dst = *t
 
-	if t == nil {
 		return nil
 	}
 
@@ -339,10 +396,10 @@ func UnmarshalReader(r io.Reader, v any) error {
 	})
 }
 
-func Marshal(v any) (res []byte, err error) {
+func Marshal(v any, opts ...Option) (res []byte, err error) {
 	buf := new(bytes.Buffer)
 
-	if err = MarshalWriter(buf, v); err != nil {
+	if err = MarshalWriter(buf, v, opts...); err != nil {
 		return
 	}
 
@@ -351,16 +408,10 @@ func Marshal(v any) (res []byte, err error) {
 	return
 }
 
-func MarshalWriter(w io.Writer, v any) (err error) {
-	t := &Tag{}
-
-	if err = marshalValue(t, v, true); err != nil {
-		return
-	}
+func MarshalWriter(w io.Writer, v any, opts ...Option) (err error) {
+	e := NewEncoder(w)
 
-	if err = newEncoder(w).encode(t); err != nil {
-		return
-	}
+	e.apply(opts)
 
-	return
+	return e.Encode(v)
 }
@@ -0,0 +1,113 @@
+package nbt
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	DefaultCompressionLevel = flate.DefaultCompression
+	BestSpeed               = flate.BestSpeed
+	BestCompression         = flate.BestCompression
+)
+
+type Compression int
+
+const (
+	CompressionNone Compression = iota
+	CompressionGzip
+	CompressionZlib
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZlib:
+		return "zlib"
+	default:
+		return "unknown"
+	}
+}
+
+// sniffCompression looks at the first two bytes of a stream and tells which compression they belong to.
+func sniffCompression(magic []byte) Compression {
+	if len(magic) < 2 {
+		return CompressionNone
+	}
+
+	if magic[0] == 0x1f && magic[1] == 0x8b {
+		return CompressionGzip
+	}
+
+	// zlib: CM=8 (deflate), CINFO<=7, no preset dictionary and a valid FCHECK.
+	// 0x08 is skipped, it's also the type byte of a raw root TAG_String.
+	if magic[0] != TypeString && magic[0]&0x0f == 0x08 && magic[0]>>4 <= 7 && magic[1]&0x20 == 0 &&
+		(uint16(magic[0])<<8|uint16(magic[1]))%31 == 0 {
+		return CompressionZlib
+	}
+
+	return CompressionNone
+}
+
+// DetectCompression sniffs the magic bytes at the start of r and returns a reader yielding the decompressed
+// NBT data together with the compression that was found.
+func DetectCompression(r io.Reader) (c Compression, dr io.Reader, err error) {
+	br, ok := r.(*bufio.Reader)
+
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	magic, err := br.Peek(2)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return
+	}
+
+	err = nil
+
+	c = sniffCompression(magic)
+
+	switch c {
+	case CompressionGzip:
+		dr, err = gzip.NewReader(br)
+	case CompressionZlib:
+		dr, err = zlib.NewReader(br)
+	default:
+		dr = br
+	}
+
+	return
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// compressWriter wraps w in a writer compressing with c. The returned writer has to be closed to flush all data.
+func compressWriter(w io.Writer, c Compression, level int) (wc io.WriteCloser, err error) {
+	switch c {
+	case CompressionNone:
+		wc = nopWriteCloser{w}
+	case CompressionGzip:
+		wc, err = gzip.NewWriterLevel(w, level)
+	case CompressionZlib:
+		wc, err = zlib.NewWriterLevel(w, level)
+	default:
+		err = fmt.Errorf("nbt: unknown compression: %d", c)
+	}
+
+	return
+}
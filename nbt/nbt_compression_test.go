@@ -0,0 +1,156 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"os"
+	"testing"
+)
+
+func compressBytes(t *testing.T, c Compression, bs []byte) []byte {
+	buf := new(bytes.Buffer)
+
+	var w io.WriteCloser
+
+	switch c {
+	case CompressionGzip:
+		w = gzip.NewWriter(buf)
+	case CompressionZlib:
+		w = zlib.NewWriter(buf)
+	default:
+		return bs
+	}
+
+	if _, err := w.Write(bs); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDetectCompression(t *testing.T) {
+	raw, err := os.ReadFile("../testdata/hello_world.nbt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []Compression{CompressionNone, CompressionGzip, CompressionZlib} {
+		detected, r, err := DetectCompression(bytes.NewReader(compressBytes(t, c, raw)))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if detected != c {
+			t.Fatalf("expected %s, got %s", c, detected)
+		}
+
+		ht := helloWorldTest{}
+
+		if err := UnmarshalReader(r, &ht); err != nil {
+			t.Fatal("error unmarshalling", err)
+		}
+
+		if ht.HelloWorld.Name != "Bananrama" {
+			t.Fatalf("expected \"Bananrama\", got \"%s\"", ht.HelloWorld.Name)
+		}
+	}
+}
+
+func TestUnmarshalCompressed(t *testing.T) {
+	raw, err := os.ReadFile("../testdata/bigtest.nbt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []Compression{CompressionGzip, CompressionZlib} {
+		bt := bigTest{}
+
+		if err := Unmarshal(compressBytes(t, c, raw), &bt); err != nil {
+			t.Fatal("error unmarshalling", err)
+		}
+
+		if bt.Level.NestedCompound.Egg.Name != "Eggbert" {
+			t.Fatalf("expected \"Eggbert\", got \"%s\"", bt.Level.NestedCompound.Egg.Name)
+		}
+	}
+}
+
+func TestMarshalKeepsCompression(t *testing.T) {
+	raw, err := os.ReadFile("../testdata/hello_world.nbt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []Compression{CompressionNone, CompressionGzip, CompressionZlib} {
+		readCompression, r, err := DetectCompression(bytes.NewReader(compressBytes(t, c, raw)))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ht := helloWorldTest{}
+
+		if err := UnmarshalReader(r, &ht); err != nil {
+			t.Fatal("error unmarshalling", err)
+		}
+
+		bs, err := Marshal(&ht, WithCompression(readCompression), WithCompressionLevel(BestCompression))
+
+		if err != nil {
+			t.Fatal("error marshalling", err)
+		}
+
+		writtenCompression, r, err := DetectCompression(bytes.NewReader(bs))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if writtenCompression != c {
+			t.Fatalf("expected %s, got %s", c, writtenCompression)
+		}
+
+		decompressed, err := io.ReadAll(r)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(decompressed, raw) {
+			t.Fatalf("expected %v, got %v", raw, decompressed)
+		}
+	}
+}
+
+func TestMarshalClosesCompressionOnError(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	tag := &Tag{Type: TypeCompound, Value: Compound{
+		"i": {Type: TypeInt, Name: []byte("i"), Value: "not an int"},
+	}}
+
+	if err := MarshalWriter(buf, tag, WithCompression(CompressionGzip)); err == nil {
+		t.Fatalf("expected error for invalid tag")
+	}
+
+	// the gzip stream is terminated even though encoding failed
+	r, err := gzip.NewReader(buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = io.ReadAll(r); err != nil {
+		t.Fatalf("expected complete gzip stream, got %v", err)
+	}
+}
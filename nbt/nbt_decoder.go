@@ -4,45 +4,144 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 )
 
+// maxPrealloc caps the capacity allocated up front for strings, arrays and lists. Lengths are read from the input,
+// larger values grow while their elements are read, so a short input can't claim gigabytes.
+const maxPrealloc = 4096
+
 type reader interface {
 	io.Reader
 	io.ByteReader
 }
 
-type decoder struct {
-	r      reader
-	numBuf *bytes.Buffer
+// Decoder reads NBT tags from an input stream. Gzip and zlib compressed streams are detected and decompressed
+// transparently.
+type Decoder struct {
+	r           reader
+	numBuf      *bytes.Buffer
+	compression Compression
+	detected    bool
+	header      BedrockHeader
+	options
 }
 
 type Unmarshaler interface {
 	UnmarshalTag(t *Tag) error
 }
 
-func newDecoder(r io.Reader) (d *decoder) {
-	d = &decoder{
-		r:      bufio.NewReader(r),
-		numBuf: bytes.NewBuffer(make([]byte, 0, 8)),
+// NewDecoder returns a new Decoder reading from r.
+func NewDecoder(r io.Reader) (d *Decoder) {
+	d = &Decoder{
+		r:       bufio.NewReader(r),
+		numBuf:  bytes.NewBuffer(make([]byte, 0, 8)),
+		options: defaultOptions(),
+	}
+
+	return
+}
+
+// SetByteOrder sets the byte order of numbers and lengths, strings follow it like with WithByteOrder. Java Edition
+// uses binary.BigEndian, which is the default.
+func (d *Decoder) SetByteOrder(order binary.ByteOrder) {
+	d.setByteOrder(order)
+}
+
+// SetNamelessRoot makes the Decoder read root tags without a name, as sent by the Java Edition network protocol
+// since 1.20.2 (protocol 764).
+func (d *Decoder) SetNamelessRoot(nameless bool) {
+	d.namelessRoot = nameless
+}
+
+// SetStrictStrings makes the Decoder reject strings that are not valid (Modified) UTF-8 instead of passing their
+// bytes through.
+func (d *Decoder) SetStrictStrings(strict bool) {
+	d.strictStrings = strict
+}
+
+// SetBedrockHeader makes the Decoder expect a BedrockHeader in front of every root tag.
+func (d *Decoder) SetBedrockHeader(enabled bool) {
+	d.bedrockHeader = enabled
+}
+
+// BedrockHeader returns the header read in front of the last root tag, see SetBedrockHeader.
+func (d *Decoder) BedrockHeader() BedrockHeader {
+	return d.header
+}
+
+// detectCompression swaps the underlying reader for a decompressing one if the stream is compressed.
+func (d *Decoder) detectCompression() (err error) {
+	if d.detected {
+		return
+	}
+
+	d.detected = true
+
+	var r io.Reader
+
+	d.compression, r, err = DetectCompression(d.r)
+
+	if err != nil {
+		return
+	}
+
+	if d.compression != CompressionNone {
+		d.r = bufio.NewReader(r)
 	}
 
 	return
 }
 
-func (d *decoder) readByte() (b byte, err error) {
+func (d *Decoder) readByte() (b byte, err error) {
 	return d.r.ReadByte()
 }
 
-func (d *decoder) readString(dst *[]byte) (err error) {
-	var size int16
+// SetDialect configures byte order and number encoding for the given Dialect.
+func (d *Decoder) SetDialect(dialect Dialect) {
+	d.setDialect(dialect)
+}
+
+func (d *Decoder) readStringLength() (size int, err error) {
+	if d.varint {
+		var v uint64
 
-	if err = d.readBE(&size); err != nil {
+		if v, err = binary.ReadUvarint(d.r); err != nil {
+			return
+		}
+
+		if v > math.MaxInt32 {
+			err = fmt.Errorf("nbt: string length out of range: %d", v)
+			return
+		}
+
+		size = int(v)
+
+		return
+	}
+
+	var v uint16
+
+	if err = d.readNumber(&v); err != nil {
+		return
+	}
+
+	size = int(v)
+
+	return
+}
+
+func (d *Decoder) readString(dst *[]byte) (err error) {
+	size, err := d.readStringLength()
+
+	if err != nil {
 		return err
 	}
 
-	res := make([]byte, 0, size)
+	res := make([]byte, 0, min(size, maxPrealloc))
 
 	var b byte
 
@@ -54,12 +153,36 @@ func (d *decoder) readString(dst *[]byte) (err error) {
 		res = append(res, b)
 	}
 
-	*dst = res
+	*dst, err = d.decodeString(res)
 
 	return
 }
 
-func (d *decoder) readBE(v any) (err error) {
+func (d *Decoder) readNumber(v any) (err error) {
+	if d.varint {
+		switch n := v.(type) {
+		case *int32:
+			var x int64
+
+			if x, err = binary.ReadVarint(d.r); err != nil {
+				return
+			}
+
+			if x < math.MinInt32 || x > math.MaxInt32 {
+				err = fmt.Errorf("nbt: varint out of int32 range: %d", x)
+				return
+			}
+
+			*n = int32(x)
+
+			return
+		case *int64:
+			*n, err = binary.ReadVarint(d.r)
+
+			return
+		}
+	}
+
 	s := binary.Size(v)
 
 	d.numBuf.Reset()
@@ -74,14 +197,14 @@ func (d *decoder) readBE(v any) (err error) {
 		d.numBuf.WriteByte(b)
 	}
 
-	_, err = binary.Decode(d.numBuf.Bytes(), binary.BigEndian, v)
+	_, err = binary.Decode(d.numBuf.Bytes(), d.byteOrder, v)
 
 	return
 }
 
 func readNumericTag[T interface {
 	int8 | int16 | int32 | int64 | float32 | float64
-}](d *decoder, named bool) (tag *Tag, err error) {
+}](d *Decoder, named bool) (tag *Tag, err error) {
 	tag = new(Tag)
 
 	if named {
@@ -92,7 +215,7 @@ func readNumericTag[T interface {
 
 	var v T
 
-	if err = d.readBE(&v); err != nil {
+	if err = d.readNumber(&v); err != nil {
 		return
 	}
 
@@ -101,7 +224,7 @@ func readNumericTag[T interface {
 	return
 }
 
-func (d *decoder) readByteTag(named bool) (tag *Tag, err error) {
+func (d *Decoder) readByteTag(named bool) (tag *Tag, err error) {
 	tag, err = readNumericTag[int8](d, named)
 
 	tag.Type = TypeByte
@@ -109,7 +232,7 @@ func (d *decoder) readByteTag(named bool) (tag *Tag, err error) {
 	return
 }
 
-func (d *decoder) readShortTag(named bool) (tag *Tag, err error) {
+func (d *Decoder) readShortTag(named bool) (tag *Tag, err error) {
 	tag, err = readNumericTag[int16](d, named)
 
 	tag.Type = TypeShort
@@ -117,7 +240,7 @@ func (d *decoder) readShortTag(named bool) (tag *Tag, err error) {
 	return
 }
 
-func (d *decoder) readIntTag(named bool) (tag *Tag, err error) {
+func (d *Decoder) readIntTag(named bool) (tag *Tag, err error) {
 	tag, err = readNumericTag[int32](d, named)
 
 	tag.Type = TypeInt
@@ -125,7 +248,7 @@ func (d *decoder) readIntTag(named bool) (tag *Tag, err error) {
 	return
 }
 
-func (d *decoder) readLongTag(named bool) (tag *Tag, err error) {
+func (d *Decoder) readLongTag(named bool) (tag *Tag, err error) {
 	tag, err = readNumericTag[int64](d, named)
 
 	tag.Type = TypeLong
@@ -133,7 +256,7 @@ func (d *decoder) readLongTag(named bool) (tag *Tag, err error) {
 	return
 }
 
-func (d *decoder) readFloatTag(named bool) (tag *Tag, err error) {
+func (d *Decoder) readFloatTag(named bool) (tag *Tag, err error) {
 	tag, err = readNumericTag[float32](d, named)
 
 	tag.Type = TypeFloat
@@ -141,7 +264,7 @@ func (d *decoder) readFloatTag(named bool) (tag *Tag, err error) {
 	return
 }
 
-func (d *decoder) readDoubleTag(named bool) (tag *Tag, err error) {
+func (d *Decoder) readDoubleTag(named bool) (tag *Tag, err error) {
 	tag, err = readNumericTag[float64](d, named)
 
 	tag.Type = TypeDouble
@@ -149,7 +272,7 @@ func (d *decoder) readDoubleTag(named bool) (tag *Tag, err error) {
 	return
 }
 
-func readArrayTag[T interface{ byte | int32 | int64 }](d *decoder, named bool) (tag *Tag, err error) {
+func readArrayTag[T interface{ byte | int32 | int64 }](d *Decoder, named bool) (tag *Tag, err error) {
 	tag = new(Tag)
 
 	if named {
@@ -168,12 +291,17 @@ func readArrayTag[T interface{ byte | int32 | int64 }](d *decoder, named bool) (
 
 	size := (sizeTag.Value).(int32)
 
-	res := make([]T, 0, size)
+	if size < 0 {
+		err = fmt.Errorf("nbt: negative array length: %d", size)
+		return
+	}
+
+	res := make([]T, 0, min(size, maxPrealloc))
 
 	var v T
 
 	for range size {
-		if err = d.readBE(&v); err != nil {
+		if err = d.readNumber(&v); err != nil {
 			return
 		}
 
@@ -185,7 +313,7 @@ func readArrayTag[T interface{ byte | int32 | int64 }](d *decoder, named bool) (
 	return
 }
 
-func (d *decoder) readByteArrayTag(named bool) (tag *Tag, err error) {
+func (d *Decoder) readByteArrayTag(named bool) (tag *Tag, err error) {
 	tag, err = readArrayTag[byte](d, named)
 
 	tag.Type = TypeByteArray
@@ -193,7 +321,7 @@ func (d *decoder) readByteArrayTag(named bool) (tag *Tag, err error) {
 	return
 }
 
-func (d *decoder) readIntArrayTag(named bool) (tag *Tag, err error) {
+func (d *Decoder) readIntArrayTag(named bool) (tag *Tag, err error) {
 	tag, err = readArrayTag[int32](d, named)
 
 	tag.Type = TypeIntArray
@@ -201,7 +329,7 @@ func (d *decoder) readIntArrayTag(named bool) (tag *Tag, err error) {
 	return
 }
 
-func (d *decoder) readLongArrayTag(named bool) (tag *Tag, err error) {
+func (d *Decoder) readLongArrayTag(named bool) (tag *Tag, err error) {
 	tag, err = readArrayTag[int64](d, named)
 
 	tag.Type = TypeLongArray
@@ -209,7 +337,7 @@ func (d *decoder) readLongArrayTag(named bool) (tag *Tag, err error) {
 	return
 }
 
-func (d *decoder) readStringTag(named bool) (tag *Tag, err error) {
+func (d *Decoder) readStringTag(named bool) (tag *Tag, err error) {
 	tag = &Tag{
 		Type: TypeString,
 	}
@@ -231,7 +359,7 @@ func (d *decoder) readStringTag(named bool) (tag *Tag, err error) {
 	return
 }
 
-func (d *decoder) readListTag(named bool) (tag *Tag, err error) {
+func (d *Decoder) readListTag(named bool) (tag *Tag, err error) {
 	tag = &Tag{
 		Type: TypeList,
 	}
@@ -250,11 +378,22 @@ func (d *decoder) readListTag(named bool) (tag *Tag, err error) {
 
 	var listSize int32
 
-	if err = d.readBE(&listSize); err != nil {
+	if err = d.readNumber(&listSize); err != nil {
+		return
+	}
+
+	if listSize < 0 {
+		err = fmt.Errorf("nbt: negative list length: %d", listSize)
+		return
+	}
+
+	// like the game, only empty lists may have TAG_End as item type
+	if listType == TypeEnd && listSize > 0 {
+		err = fmt.Errorf("nbt: list of %d items has item type TAG_End", listSize)
 		return
 	}
 
-	res := make(List, 0, listSize)
+	res := make(List, 0, min(listSize, maxPrealloc))
 
 	var listItemTag *Tag
 
@@ -273,7 +412,7 @@ func (d *decoder) readListTag(named bool) (tag *Tag, err error) {
 	return
 }
 
-func (d *decoder) readCompoundTag(named bool) (tag *Tag, err error) {
+func (d *Decoder) readCompoundTag(named bool) (tag *Tag, err error) {
 	tag = &Tag{
 		Type: TypeCompound,
 	}
@@ -305,7 +444,7 @@ func (d *decoder) readCompoundTag(named bool) (tag *Tag, err error) {
 	return
 }
 
-func (d *decoder) readNextTag(tagType int) (tag *Tag, err error) {
+func (d *Decoder) readNextTag(tagType int) (tag *Tag, err error) {
 	named := false
 
 	if tagType == -1 {
@@ -321,6 +460,10 @@ func (d *decoder) readNextTag(tagType int) (tag *Tag, err error) {
 		named = true
 	}
 
+	return d.readTag(tagType, named)
+}
+
+func (d *Decoder) readTag(tagType int, named bool) (tag *Tag, err error) {
 	switch tagType {
 	case TypeEnd:
 		return
@@ -353,6 +496,80 @@ func (d *decoder) readNextTag(tagType int) (tag *Tag, err error) {
 	}
 }
 
-func (d *decoder) decode() (tag *Tag, err error) {
-	return d.readNextTag(-1)
+// readBedrockHeader reads the remainder of a BedrockHeader whose first byte was already consumed.
+func (d *Decoder) readBedrockHeader(first byte) (err error) {
+	buf := make([]byte, 8)
+
+	buf[0] = first
+
+	if _, err = io.ReadFull(d.r, buf[1:]); err != nil {
+		return
+	}
+
+	d.header = BedrockHeader{
+		Version: int32(binary.LittleEndian.Uint32(buf[0:4])),
+		Length:  int32(binary.LittleEndian.Uint32(buf[4:8])),
+	}
+
+	return
+}
+
+func (d *Decoder) decode() (tag *Tag, err error) {
+	if err = d.detectCompression(); err != nil {
+		return
+	}
+
+	// io.EOF while reading the first byte means the stream ended cleanly between two root tags
+	tagType, err := d.readByte()
+
+	if err != nil {
+		return
+	}
+
+	if d.bedrockHeader {
+		if err = d.readBedrockHeader(tagType); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return
+		}
+
+		if tagType, err = d.readByte(); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return
+		}
+	}
+
+	tag, err = d.readTag(int(tagType), !d.namelessRoot)
+
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return
+}
+
+// Decode reads the next root tag from the input and stores it in v. It can be called repeatedly to read
+// a stream of concatenated tags and returns io.EOF once the stream ends at a tag boundary.
+func (d *Decoder) Decode(v any) (err error) {
+	t, err := d.decode()
+
+	if err != nil {
+		return
+	}
+
+	if t == nil {
+		return
+	}
+
+	return unmarshalRoot(v, t)
+}
+
+// Compression returns the compression detected on the input stream. It is only known after the first call to Decode.
+func (d *Decoder) Compression() Compression {
+	return d.compression
 }
@@ -1,73 +1,185 @@
 package nbt
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"math"
+	"slices"
 )
 
 var zeroBytes = []byte{0}
 
-type encoder struct {
-	w io.Writer
+// Encoder writes NBT tags to an output stream.
+type Encoder struct {
+	out io.Writer
+	w   io.Writer
+	options
 }
 
-func newEncoder(w io.Writer) (e *encoder) {
-	e = &encoder{
-		w: w,
+// NewEncoder returns a new Encoder writing uncompressed, big endian NBT to w.
+func NewEncoder(w io.Writer) (e *Encoder) {
+	e = &Encoder{
+		out:     w,
+		w:       w,
+		options: defaultOptions(),
 	}
 
 	return
 }
 
-func (e *encoder) writeType(t *Tag) (err error) {
+// SetCompression sets the compression of each tag written by Encode. Every call to Encode produces a complete
+// gzip member or zlib stream.
+func (e *Encoder) SetCompression(c Compression) {
+	e.compression = c
+}
+
+// SetCompressionLevel sets the level used by SetCompression, see compress/flate for valid values.
+func (e *Encoder) SetCompressionLevel(level int) {
+	e.compressionLevel = level
+}
+
+// SetByteOrder sets the byte order of numbers and lengths, strings follow it like with WithByteOrder. Java Edition
+// uses binary.BigEndian, which is the default.
+func (e *Encoder) SetByteOrder(order binary.ByteOrder) {
+	e.setByteOrder(order)
+}
+
+// SetNamelessRoot makes the Encoder write root tags without a name, as expected by the Java Edition network
+// protocol since 1.20.2 (protocol 764).
+func (e *Encoder) SetNamelessRoot(nameless bool) {
+	e.namelessRoot = nameless
+}
+
+// SetStrictStrings makes the Encoder reject strings that are not valid UTF-8 instead of passing their bytes through.
+func (e *Encoder) SetStrictStrings(strict bool) {
+	e.strictStrings = strict
+}
+
+// SetBedrockHeader makes the Encoder write a BedrockHeader carrying version in front of every root tag.
+func (e *Encoder) SetBedrockHeader(version int32) {
+	e.bedrockHeader = true
+	e.bedrockVersion = version
+}
+
+// SetSortKeys makes the Encoder write compound entries sorted by name instead of in map order.
+func (e *Encoder) SetSortKeys(sortKeys bool) {
+	e.sortKeys = sortKeys
+}
+
+func (e *Encoder) writeType(t *Tag) (err error) {
 	_, err = e.w.Write([]byte{byte(t.Type)})
 	return
 }
 
-func (e *encoder) writeName(t *Tag) (err error) {
-	nameLen := uint16(len(t.Name))
-	nameLenBytes := make([]byte, 2)
-
-	binary.BigEndian.PutUint16(nameLenBytes, nameLen)
+// SetDialect configures byte order and number encoding for the given Dialect.
+func (e *Encoder) SetDialect(dialect Dialect) {
+	e.setDialect(dialect)
+}
 
-	if _, err = e.w.Write(nameLenBytes); err != nil {
+func (e *Encoder) writeString(bs []byte) (err error) {
+	if bs, err = e.encodeString(bs); err != nil {
 		return
 	}
 
-	if _, err = e.w.Write(t.Name); err != nil {
-		return
+	if e.varint {
+		if _, err = e.w.Write(binary.AppendUvarint(nil, uint64(len(bs)))); err != nil {
+			return
+		}
+	} else {
+		if len(bs) > math.MaxUint16 {
+			err = fmt.Errorf("nbt: string too long: %d bytes", len(bs))
+			return
+		}
+
+		if err = e.writeNumber(uint16(len(bs))); err != nil {
+			return
+		}
+	}
+
+	_, err = e.w.Write(bs)
+
+	return
+}
+
+func (e *Encoder) writeName(t *Tag) (err error) {
+	return e.writeString(t.Name)
+}
+
+func (e *Encoder) writeNumber(v any) (err error) {
+	if e.varint {
+		switch n := v.(type) {
+		case int32:
+			_, err = e.w.Write(binary.AppendVarint(nil, int64(n)))
+			return
+		case int64:
+			_, err = e.w.Write(binary.AppendVarint(nil, n))
+			return
+		}
+	}
+
+	return binary.Write(e.w, e.byteOrder, v)
+}
+
+// payload returns the value of t, which has to be of type T.
+func payload[T any](t *Tag) (v T, err error) {
+	v, ok := t.Value.(T)
+
+	if !ok {
+		err = fmt.Errorf("nbt: TAG_%s %q has value of type %T", typeName(t.Type), t.Name, t.Value)
 	}
 
 	return
 }
 
-func (e *encoder) writeBE(v any) (err error) {
-	return binary.Write(e.w, binary.BigEndian, v)
+// writeNumberPayload writes the value of t, which has to be of type T.
+func writeNumberPayload[T int8 | int16 | int32 | int64 | float32 | float64](e *Encoder, t *Tag) (err error) {
+	v, err := payload[T](t)
+
+	if err != nil {
+		return
+	}
+
+	return e.writeNumber(v)
 }
 
-func (e *encoder) writePayload(t *Tag) (err error) {
+func (e *Encoder) writePayload(t *Tag) (err error) {
 	switch t.Type {
 	case TypeEnd:
 		_, err = e.w.Write(zeroBytes)
 	case TypeByte:
-		err = e.writeBE(t.Value.(int8))
+		err = writeNumberPayload[int8](e, t)
 	case TypeShort:
-		err = e.writeBE(t.Value.(int16))
+		err = writeNumberPayload[int16](e, t)
 	case TypeInt:
-		err = e.writeBE(t.Value.(int32))
+		err = writeNumberPayload[int32](e, t)
 	case TypeLong:
-		err = e.writeBE(t.Value.(int64))
+		err = writeNumberPayload[int64](e, t)
 	case TypeFloat:
-		err = e.writeBE(t.Value.(float32))
+		err = writeNumberPayload[float32](e, t)
 	case TypeDouble:
-		err = e.writeBE(t.Value.(float64))
+		err = writeNumberPayload[float64](e, t)
 	case TypeCompound:
-		tagCompound := t.Value.(Compound)
+		var tagCompound Compound
 
-		for _, tag := range tagCompound {
-			if err = e.encodeTag(tag, true); err != nil {
-				return
+		if tagCompound, err = payload[Compound](t); err != nil {
+			return
+		}
+
+		if e.sortKeys {
+			for _, name := range slices.Sorted(maps.Keys(tagCompound)) {
+				if err = e.encodeEntry(t, name, tagCompound[name]); err != nil {
+					return
+				}
+			}
+		} else {
+			for name, tag := range tagCompound {
+				if err = e.encodeEntry(t, name, tag); err != nil {
+					return
+				}
 			}
 		}
 
@@ -75,53 +187,115 @@ func (e *encoder) writePayload(t *Tag) (err error) {
 			return
 		}
 	case TypeList:
-		tagList := t.Value.(List)
-		size := int32(len(tagList))
+		var tagList List
 
-		if size == 0 {
-			err = fmt.Errorf("nbt: cannot encode empty list")
+		if tagList, err = payload[List](t); err != nil {
 			return
 		}
 
-		itemType := tagList[0].Type
+		size := int32(len(tagList))
+
+		// empty lists have no item type, like the game we write TAG_End
+		itemType := TypeEnd
+
+		if size > 0 && tagList[0] != nil {
+			itemType = tagList[0].Type
+		}
 
 		if _, err = e.w.Write([]byte{byte(itemType)}); err != nil {
 			return
 		}
 
-		if err = e.writeBE(size); err != nil {
+		if err = e.writeNumber(size); err != nil {
 			return
 		}
 
-		for _, tagListItem := range tagList {
+		for i, tagListItem := range tagList {
+			if tagListItem == nil || tagListItem.Type != itemType {
+				return fmt.Errorf("nbt: list item %d of %q does not have the list's item type TAG_%s", i, t.Name, typeName(itemType))
+			}
+
 			if err = e.encodeTag(tagListItem, false); err != nil {
 				return
 			}
 		}
 	case TypeByteArray:
-		size := int32(len(t.Value.([]byte)))
+		var bs []byte
+
+		if bs, err = payload[[]byte](t); err != nil {
+			return
+		}
 
-		if err = e.writeBE(size); err != nil {
+		if err = e.writeNumber(int32(len(bs))); err != nil {
 			return
 		}
 
-		_, err = e.w.Write(t.Value.([]byte))
+		_, err = e.w.Write(bs)
+	case TypeIntArray:
+		var values []int32
+
+		if values, err = payload[[]int32](t); err != nil {
+			return
+		}
+
+		err = writeArray(e, values)
+	case TypeLongArray:
+		var values []int64
+
+		if values, err = payload[[]int64](t); err != nil {
+			return
+		}
+
+		err = writeArray(e, values)
 	case TypeString:
-		size := int16(len(t.Value.(string)))
+		var str string
 
-		if err = e.writeBE(size); err != nil {
+		if str, err = payload[string](t); err != nil {
 			return
 		}
 
-		_, err = e.w.Write([]byte(t.Value.(string)))
+		err = e.writeString([]byte(str))
 	default:
-		_, err = e.w.Write(t.Value.([]byte))
+		var bs []byte
+
+		if bs, err = payload[[]byte](t); err != nil {
+			return
+		}
+
+		_, err = e.w.Write(bs)
 	}
 
 	return
 }
 
-func (e *encoder) encodeTag(t *Tag, named bool) (err error) {
+func writeArray[T interface{ int32 | int64 }](e *Encoder, values []T) (err error) {
+	if err = e.writeNumber(int32(len(values))); err != nil {
+		return
+	}
+
+	if !e.varint {
+		return binary.Write(e.w, e.byteOrder, values)
+	}
+
+	for _, v := range values {
+		if err = e.writeNumber(v); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// encodeEntry writes the entry name of compound c.
+func (e *Encoder) encodeEntry(c *Tag, name string, t *Tag) error {
+	if t == nil {
+		return fmt.Errorf("nbt: compound entry %q of %q is nil", name, c.Name)
+	}
+
+	return e.encodeTag(t, true)
+}
+
+func (e *Encoder) encodeTag(t *Tag, named bool) (err error) {
 	if named {
 		if err = e.writeType(t); err != nil {
 			return
@@ -135,6 +309,67 @@ func (e *encoder) encodeTag(t *Tag, named bool) (err error) {
 	return e.writePayload(t)
 }
 
-func (e *encoder) encode(t *Tag) (err error) {
-	return e.encodeTag(t, true)
+func (e *Encoder) encodeRoot(t *Tag) (err error) {
+	if !e.namelessRoot {
+		return e.encodeTag(t, true)
+	}
+
+	if err = e.writeType(t); err != nil {
+		return
+	}
+
+	return e.writePayload(t)
+}
+
+func (e *Encoder) encode(t *Tag) (err error) {
+	cw, err := compressWriter(e.out, e.compression, e.compressionLevel)
+
+	if err != nil {
+		return
+	}
+
+	// the compressing writer is closed on errors as well, so it doesn't hold on to its resources
+	defer func() {
+		e.w = e.out
+		err = errors.Join(err, cw.Close())
+	}()
+
+	if !e.bedrockHeader {
+		e.w = cw
+
+		return e.encodeRoot(t)
+	}
+
+	// the header contains the length of the payload, so it has to be buffered first
+	buf := new(bytes.Buffer)
+
+	e.w = buf
+
+	if err = e.encodeRoot(t); err != nil {
+		return
+	}
+
+	header := make([]byte, 8)
+
+	binary.LittleEndian.PutUint32(header[0:4], uint32(e.bedrockVersion))
+	binary.LittleEndian.PutUint32(header[4:8], uint32(buf.Len()))
+
+	if _, err = cw.Write(header); err != nil {
+		return
+	}
+
+	_, err = buf.WriteTo(cw)
+
+	return
+}
+
+// Encode writes v as the next root tag to the output. v is either a *Tag or a value supported by Marshal.
+func (e *Encoder) Encode(v any) (err error) {
+	t := &Tag{}
+
+	if err = marshalValue(t, v, true); err != nil {
+		return
+	}
+
+	return e.encode(t)
 }
@@ -0,0 +1,211 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestEncoderMultipleTags(t *testing.T) {
+	f, err := os.Open("../testdata/bigtest.nbt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	bigTestTag := Tag{}
+
+	if err := NewDecoder(f).Decode(&bigTestTag); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+
+	e := NewEncoder(buf)
+
+	e.SetCompression(CompressionGzip)
+	e.SetSortKeys(true)
+
+	for range 3 {
+		if err := e.Encode(&bigTestTag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := NewDecoder(buf)
+
+	for range 3 {
+		bt := bigTest{}
+
+		if err := d.Decode(&bt); err != nil {
+			t.Fatal(err)
+		}
+
+		if bt.Level.StringTest != "HELLO WORLD THIS IS A TEST STRING ÅÄÖ!" {
+			t.Fatalf("expected \"HELLO WORLD THIS IS A TEST STRING ÅÄÖ!\", got \"%s\"", bt.Level.StringTest)
+		}
+	}
+
+	if d.Compression() != CompressionGzip {
+		t.Fatalf("expected gzip, got %s", d.Compression())
+	}
+
+	if err := d.Decode(&bigTestTag); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestEncoderSortKeys(t *testing.T) {
+	tag := &Tag{
+		Type: TypeCompound,
+		Name: []byte("root"),
+		Value: Compound{
+			"b": {Type: TypeByte, Name: []byte("b"), Value: int8(2)},
+			"a": {Type: TypeByte, Name: []byte("a"), Value: int8(1)},
+			"c": {Type: TypeByte, Name: []byte("c"), Value: int8(3)},
+		},
+	}
+
+	expected := []byte{
+		TypeCompound, 0, 4, 'r', 'o', 'o', 't',
+		TypeByte, 0, 1, 'a', 1,
+		TypeByte, 0, 1, 'b', 2,
+		TypeByte, 0, 1, 'c', 3,
+		TypeEnd,
+	}
+
+	for range 10 {
+		bs, err := Marshal(tag, WithSortedKeys())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(bs, expected) {
+			t.Fatalf("expected %v, got %v", expected, bs)
+		}
+	}
+}
+
+func TestEncoderByteOrder(t *testing.T) {
+	tag := &Tag{
+		Type:  TypeInt,
+		Name:  []byte("i"),
+		Value: int32(0x01020304),
+	}
+
+	buf := new(bytes.Buffer)
+
+	e := NewEncoder(buf)
+
+	e.SetByteOrder(binary.LittleEndian)
+
+	if err := e.Encode(tag); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{TypeInt, 1, 0, 'i', 4, 3, 2, 1}
+
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Fatalf("expected %v, got %v", expected, buf.Bytes())
+	}
+}
+
+func TestEncoderEmptyList(t *testing.T) {
+	tag := &Tag{
+		Type:  TypeList,
+		Name:  []byte("l"),
+		Value: List{},
+	}
+
+	bs, err := Marshal(tag)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{TypeList, 0, 1, 'l', TypeEnd, 0, 0, 0, 0}
+
+	if !bytes.Equal(bs, expected) {
+		t.Fatalf("expected %v, got %v", expected, bs)
+	}
+}
+
+func TestEncoderMixedList(t *testing.T) {
+	if bs, err := Marshal(map[string]any{"l": []any{int32(1), "hello"}}); err == nil {
+		t.Fatalf("expected error for list items of different types, got %v", bs)
+	}
+
+	tag := &Tag{
+		Type: TypeList,
+		Name: []byte("l"),
+		Value: List{
+			{Type: TypeInt, Value: int32(1)},
+			{Type: TypeString, Value: "hello"},
+		},
+	}
+
+	if bs, err := Marshal(tag); err == nil {
+		t.Fatalf("expected error for list items of different types, got %v", bs)
+	}
+}
+
+func TestEncoderValueMismatch(t *testing.T) {
+	tags := []*Tag{
+		{Type: TypeInt, Name: []byte("i"), Value: int16(1)},
+		{Type: TypeList, Name: []byte("l"), Value: []int32{1}},
+		{Type: TypeByteArray, Name: []byte("b"), Value: "bytes"},
+		{Type: TypeCompound, Name: []byte("c"), Value: Compound{"x": nil}},
+		{Type: TypeCompound, Name: []byte("c"), Value: Compound{
+			"s": {Type: TypeString, Name: []byte("s"), Value: 1},
+		}},
+	}
+
+	for _, tag := range tags {
+		if bs, err := Marshal(tag); err == nil {
+			t.Fatalf("expected error for %v, got %v", tag.Value, bs)
+		}
+	}
+}
+
+func TestByteOrderSelectsStrings(t *testing.T) {
+	tag := &Tag{Type: TypeString, Name: []byte("s"), Value: "nul\x00 and 😀"}
+
+	byOrder, err := Marshal(tag, WithByteOrder(binary.LittleEndian))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byDialect, err := Marshal(tag, WithDialect(DialectBedrock))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(byOrder, byDialect) {
+		t.Fatalf("expected %v, got %v", byDialect, byOrder)
+	}
+
+	javaOrder, err := Marshal(tag, WithDialect(DialectBedrock), WithByteOrder(binary.BigEndian))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	java, err := Marshal(tag)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(javaOrder, java) {
+		t.Fatalf("expected %v, got %v", java, javaOrder)
+	}
+}
@@ -0,0 +1,105 @@
+package nbt
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// IntArray marshals as TAG_Int_Array, while []int32 marshals as a list of ints.
+type IntArray []int32
+
+// LongArray marshals as TAG_Long_Array, while []int64 marshals as a list of longs.
+type LongArray []int64
+
+// field is a struct field mapped to a compound entry.
+type field struct {
+	name      string
+	index     []int
+	depth     int
+	omitEmpty bool
+}
+
+// structFields describes how the fields of a struct type map to compound entries. Fields of embedded structs
+// without an nbt tag are promoted, fields closer to the outer struct hide deeper ones of the same name.
+//
+// Options follow the name, separated by commas: omitempty leaves zero values out when marshalling and rest marks
+// a Compound receiving all entries not mapped to another field. Only these suffixes are treated as options, names
+// may contain commas themselves.
+type structFields struct {
+	fields []field
+	rest   []int
+}
+
+func parseFieldTag(tag string) (name string, omitEmpty bool, rest bool) {
+	name = tag
+
+	for {
+		if n, found := strings.CutSuffix(name, ",omitempty"); found {
+			name, omitEmpty = n, true
+		} else if n, found := strings.CutSuffix(name, ",rest"); found {
+			name, rest = n, true
+		} else {
+			return
+		}
+	}
+}
+
+func fieldsOf(typ reflect.Type) (s structFields) {
+	restDepth := -1
+	minDepth := map[string]int{}
+
+	var collect func(typ reflect.Type, index []int, depth int)
+
+	collect = func(typ reflect.Type, index []int, depth int) {
+		for i := 0; i < typ.NumField(); i++ {
+			sf := typ.Field(i)
+			fieldIndex := append(append([]int{}, index...), i)
+
+			tag, hasTag := sf.Tag.Lookup("nbt")
+
+			if !hasTag {
+				if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+					collect(sf.Type, fieldIndex, depth+1)
+				}
+
+				continue
+			}
+
+			name, omitEmpty, rest := parseFieldTag(tag)
+
+			if rest {
+				if sf.Type == reflect.TypeOf(Compound{}) && (restDepth == -1 || depth < restDepth) {
+					s.rest, restDepth = fieldIndex, depth
+				}
+
+				continue
+			}
+
+			if d, ok := minDepth[name]; !ok || depth < d {
+				minDepth[name] = depth
+			}
+
+			s.fields = append(s.fields, field{name: name, index: fieldIndex, depth: depth, omitEmpty: omitEmpty})
+		}
+	}
+
+	collect(typ, nil, 0)
+
+	s.fields = slices.DeleteFunc(s.fields, func(f field) bool {
+		return f.depth > minDepth[f.name]
+	})
+
+	return
+}
+
+// has reports whether an entry name is mapped to a field.
+func (s structFields) has(name string) bool {
+	for _, f := range s.fields {
+		if f.name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,233 @@
+package nbt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// The JSON representation of a Tag is {"type": <type>, "name": <name>, "value": <value>} and keeps every NBT type:
+//
+//   - bytes, shorts and ints are numbers
+//   - longs are strings, JSON numbers can't hold every int64
+//   - floats and doubles are numbers, NaN and infinities are the strings "NaN", "Infinity" and "-Infinity"
+//   - byte arrays are base64 strings, int arrays are arrays of numbers and long arrays are arrays of strings
+//   - lists are arrays of tags, compounds are objects mapping names to tags
+
+type jsonTag struct {
+	Type  int             `json:"type"`
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (t *Tag) MarshalJSON() (bs []byte, err error) {
+	var v any
+
+	switch value := t.Value.(type) {
+	case int64:
+		v = strconv.FormatInt(value, 10)
+	case float32:
+		v = jsonFloat(float64(value), value)
+	case float64:
+		v = jsonFloat(value, value)
+	case []int64:
+		ls := make([]string, len(value))
+
+		for i, l := range value {
+			ls[i] = strconv.FormatInt(l, 10)
+		}
+
+		v = ls
+	default:
+		v = value
+	}
+
+	return json.Marshal(struct {
+		Type  int    `json:"type"`
+		Name  string `json:"name"`
+		Value any    `json:"value"`
+	}{
+		Type:  t.Type,
+		Name:  string(t.Name),
+		Value: v,
+	})
+}
+
+// jsonFloat returns non-finite floats as strings, everything else as it is.
+func jsonFloat(f float64, v any) any {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	default:
+		return v
+	}
+}
+
+func (t *Tag) UnmarshalJSON(bs []byte) (err error) {
+	jt := jsonTag{}
+
+	if err = json.Unmarshal(bs, &jt); err != nil {
+		return
+	}
+
+	t.Type = jt.Type
+	t.Name = nil
+
+	if jt.Name != "" {
+		t.Name = []byte(jt.Name)
+	}
+
+	switch t.Type {
+	case TypeByte:
+		t.Value, err = unmarshalJSONInt[int8](jt.Value, 8)
+	case TypeShort:
+		t.Value, err = unmarshalJSONInt[int16](jt.Value, 16)
+	case TypeInt:
+		t.Value, err = unmarshalJSONInt[int32](jt.Value, 32)
+	case TypeLong:
+		t.Value, err = unmarshalJSONLong(jt.Value)
+	case TypeFloat:
+		var f float64
+
+		f, err = unmarshalJSONFloat(jt.Value, 32)
+		t.Value = float32(f)
+	case TypeDouble:
+		t.Value, err = unmarshalJSONFloat(jt.Value, 64)
+	case TypeByteArray:
+		bs := []byte{}
+
+		err = json.Unmarshal(jt.Value, &bs)
+		t.Value = bs
+	case TypeString:
+		var s string
+
+		err = json.Unmarshal(jt.Value, &s)
+		t.Value = s
+	case TypeList:
+		l := List{}
+
+		err = json.Unmarshal(jt.Value, &l)
+		t.Value = l
+	case TypeCompound:
+		c := Compound{}
+
+		err = json.Unmarshal(jt.Value, &c)
+		t.Value = c
+	case TypeIntArray:
+		is := []int32{}
+
+		err = json.Unmarshal(jt.Value, &is)
+		t.Value = is
+	case TypeLongArray:
+		var raw []json.RawMessage
+
+		if err = json.Unmarshal(jt.Value, &raw); err != nil {
+			return
+		}
+
+		ls := make([]int64, len(raw))
+
+		for i, r := range raw {
+			if ls[i], err = unmarshalJSONLong(r); err != nil {
+				return
+			}
+		}
+
+		t.Value = ls
+	default:
+		err = fmt.Errorf("nbt: unknown Tag type: %d", t.Type)
+	}
+
+	return
+}
+
+func (c *Compound) UnmarshalJSON(bs []byte) (err error) {
+	var tags map[string]*Tag
+
+	if err = json.Unmarshal(bs, &tags); err != nil {
+		return
+	}
+
+	*c = make(Compound, len(tags))
+
+	for name, tag := range tags {
+		if tag == nil {
+			return fmt.Errorf("nbt: compound entry %q is null", name)
+		}
+
+		// the key is authoritative, the name inside the tag is redundant
+		tag.Name = []byte(name)
+		(*c)[name] = tag
+	}
+
+	return
+}
+
+func (l *List) UnmarshalJSON(bs []byte) (err error) {
+	var tags []*Tag
+
+	if err = json.Unmarshal(bs, &tags); err != nil {
+		return
+	}
+
+	for i, tag := range tags {
+		if tag == nil {
+			return fmt.Errorf("nbt: list item %d is null", i)
+		}
+	}
+
+	*l = append(List{}, tags...)
+
+	return
+}
+
+func unmarshalJSONInt[T interface{ int8 | int16 | int32 }](bs []byte, bitSize int) (v T, err error) {
+	var n json.Number
+
+	if err = json.Unmarshal(bs, &n); err != nil {
+		return
+	}
+
+	i, err := strconv.ParseInt(n.String(), 10, bitSize)
+
+	return T(i), err
+}
+
+// unmarshalJSONLong accepts longs as strings and, for hand written JSON, as numbers.
+func unmarshalJSONLong(bs []byte) (v int64, err error) {
+	var n json.Number
+
+	if err = json.Unmarshal(bs, &n); err != nil {
+		return
+	}
+
+	return strconv.ParseInt(n.String(), 10, 64)
+}
+
+func unmarshalJSONFloat(bs []byte, bitSize int) (f float64, err error) {
+	var s string
+
+	if json.Unmarshal(bs, &s) == nil {
+		switch s {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+	}
+
+	var n json.Number
+
+	if err = json.Unmarshal(bs, &n); err != nil {
+		return
+	}
+
+	return strconv.ParseFloat(n.String(), bitSize)
+}
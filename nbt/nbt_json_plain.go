@@ -0,0 +1,460 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Plain converts t into plain Go values for human consumers: compounds become map[string]any, lists become []any,
+// arrays become slices of numbers and everything else keeps its value. Non-finite floats become nil, JSON can't
+// represent them.
+func (t *Tag) Plain() any {
+	switch v := t.Value.(type) {
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil
+		}
+
+		return v
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+
+		return v
+	case []byte:
+		// []byte would be marshalled as base64
+		res := make([]int8, len(v))
+
+		for i, b := range v {
+			res[i] = int8(b)
+		}
+
+		return res
+	case List:
+		res := make([]any, len(v))
+
+		for i, item := range v {
+			res[i] = item.Plain()
+		}
+
+		return res
+	case Compound:
+		res := make(map[string]any, len(v))
+
+		for name, child := range v {
+			res[name] = child.Plain()
+		}
+
+		return res
+	default:
+		return v
+	}
+}
+
+// MarshalPlainJSON returns the JSON encoding of t.Plain().
+func MarshalPlainJSON(t *Tag) ([]byte, error) {
+	return json.Marshal(t.Plain())
+}
+
+// UnmarshalPlainJSON builds a tag from plain JSON as produced by MarshalPlainJSON. Without a hint, NBT types are
+// inferred: objects become compounds, arrays become lists, booleans become bytes, integers become ints (longs if
+// they don't fit) and all other numbers become doubles.
+//
+// hint is either a struct (or pointer to one) whose field types and nbt tags tell the types, using the same mapping
+// as Marshal, or a *Tag serving as schema. Values not covered by the hint are inferred.
+func UnmarshalPlainJSON(bs []byte, hint any) (tag *Tag, err error) {
+	d := json.NewDecoder(bytes.NewReader(bs))
+
+	d.UseNumber()
+
+	var v any
+
+	if err = d.Decode(&v); err != nil {
+		return
+	}
+
+	var s *plainSchema
+
+	switch h := hint.(type) {
+	case nil:
+	case *Tag:
+		s = schemaFromTag(h)
+	default:
+		s = schemaFromType(reflect.TypeOf(hint))
+	}
+
+	return plainToTag(v, s, "")
+}
+
+// plainSchema describes the NBT types expected for a plain value. A zero typ means the type is inferred.
+type plainSchema struct {
+	typ    int
+	fields map[string]*plainSchema
+	elem   *plainSchema
+}
+
+func (s *plainSchema) field(name string) *plainSchema {
+	if s == nil {
+		return nil
+	}
+
+	if f, ok := s.fields[name]; ok {
+		return f
+	}
+
+	return s.elem
+}
+
+func (s *plainSchema) items() *plainSchema {
+	if s == nil {
+		return nil
+	}
+
+	return s.elem
+}
+
+func (s *plainSchema) tagType() int {
+	if s == nil {
+		return TypeEnd
+	}
+
+	return s.typ
+}
+
+func schemaFromTag(t *Tag) (s *plainSchema) {
+	s = &plainSchema{typ: t.Type}
+
+	switch v := t.Value.(type) {
+	case Compound:
+		s.fields = make(map[string]*plainSchema, len(v))
+
+		for name, child := range v {
+			s.fields[name] = schemaFromTag(child)
+		}
+	case List:
+		if len(v) > 0 {
+			s.elem = schemaFromTag(v[0])
+		}
+	}
+
+	return
+}
+
+func schemaFromType(typ reflect.Type) (s *plainSchema) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ {
+	case reflect.TypeOf(Tag{}), reflect.TypeOf(List{}), reflect.TypeOf(Compound{}):
+		return nil
+	case reflect.TypeOf(IntArray{}):
+		return &plainSchema{typ: TypeIntArray}
+	case reflect.TypeOf(LongArray{}):
+		return &plainSchema{typ: TypeLongArray}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return &plainSchema{typ: TypeByte}
+	case reflect.Int16:
+		return &plainSchema{typ: TypeShort}
+	case reflect.Int32:
+		return &plainSchema{typ: TypeInt}
+	case reflect.Int64:
+		return &plainSchema{typ: TypeLong}
+	case reflect.Float32:
+		return &plainSchema{typ: TypeFloat}
+	case reflect.Float64:
+		return &plainSchema{typ: TypeDouble}
+	case reflect.String:
+		return &plainSchema{typ: TypeString}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &plainSchema{typ: TypeByteArray}
+		}
+
+		return &plainSchema{typ: TypeList, elem: schemaFromType(typ.Elem())}
+	case reflect.Map:
+		return &plainSchema{typ: TypeCompound, elem: schemaFromType(typ.Elem())}
+	case reflect.Struct:
+		s = &plainSchema{typ: TypeCompound, fields: map[string]*plainSchema{}}
+
+		for _, f := range fieldsOf(typ).fields {
+			s.fields[f.name] = schemaFromType(typ.FieldByIndex(f.index).Type)
+		}
+
+		return
+	default:
+		return nil
+	}
+}
+
+// inferPlainType returns the NBT type for a plain value without a hint.
+func inferPlainType(v any) (int, error) {
+	switch n := v.(type) {
+	case bool:
+		return TypeByte, nil
+	case string:
+		return TypeString, nil
+	case map[string]any:
+		return TypeCompound, nil
+	case []any:
+		return TypeList, nil
+	case json.Number:
+		if i, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+			if i >= math.MinInt32 && i <= math.MaxInt32 {
+				return TypeInt, nil
+			}
+
+			return TypeLong, nil
+		}
+
+		return TypeDouble, nil
+	default:
+		return TypeEnd, fmt.Errorf("unsupported value: %v", v)
+	}
+}
+
+// inferPlainListType finds a type all items of a list can be converted to, widening mixed numbers.
+func inferPlainListType(items []any) (typ int, err error) {
+	for _, item := range items {
+		var itemType int
+
+		if itemType, err = inferPlainType(item); err != nil {
+			return
+		}
+
+		switch {
+		case typ == TypeEnd || typ == itemType:
+			typ = itemType
+		case isPlainNumber(typ) && isPlainNumber(itemType):
+			typ = max(typ, itemType)
+		default:
+			return TypeEnd, fmt.Errorf("list items have different types")
+		}
+	}
+
+	return
+}
+
+func isPlainNumber(typ int) bool {
+	return typ == TypeInt || typ == TypeLong || typ == TypeDouble
+}
+
+func plainToTag(v any, s *plainSchema, path string) (tag *Tag, err error) {
+	tag = &Tag{Type: s.tagType()}
+
+	if tag.Type == TypeEnd {
+		if tag.Type, err = inferPlainType(v); err != nil {
+			return nil, fmt.Errorf("nbt: %s: %w", pathOrRoot(path), err)
+		}
+	}
+
+	if tag.Value, err = plainValue(v, tag.Type, s, path); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+
+	return path
+}
+
+func plainValue(v any, typ int, s *plainSchema, path string) (res any, err error) {
+	mismatch := func() error {
+		return fmt.Errorf("nbt: %s: cannot convert %v to tag type %d", pathOrRoot(path), v, typ)
+	}
+
+	switch typ {
+	case TypeByte, TypeShort, TypeInt, TypeLong:
+		var i int64
+
+		switch n := v.(type) {
+		case bool:
+			if n {
+				i = 1
+			}
+		case json.Number:
+			if i, err = strconv.ParseInt(n.String(), 10, 64); err != nil {
+				return nil, mismatch()
+			}
+		default:
+			return nil, mismatch()
+		}
+
+		return plainInt(i, typ, mismatch)
+	case TypeFloat, TypeDouble:
+		n, ok := v.(json.Number)
+
+		if !ok {
+			return nil, mismatch()
+		}
+
+		var f float64
+
+		if f, err = n.Float64(); err != nil {
+			return nil, mismatch()
+		}
+
+		if typ == TypeFloat {
+			return float32(f), nil
+		}
+
+		return f, nil
+	case TypeString:
+		if str, ok := v.(string); ok {
+			return str, nil
+		}
+
+		return nil, mismatch()
+	case TypeByteArray, TypeIntArray, TypeLongArray:
+		return plainArray(v, typ, path)
+	case TypeList:
+		items, ok := v.([]any)
+
+		if !ok {
+			return nil, mismatch()
+		}
+
+		itemSchema := s.items()
+
+		if itemSchema.tagType() == TypeEnd {
+			var itemType int
+
+			if itemType, err = inferPlainListType(items); err != nil {
+				return nil, fmt.Errorf("nbt: %s: %w", pathOrRoot(path), err)
+			}
+
+			itemSchema = &plainSchema{typ: itemType}
+		}
+
+		l := make(List, 0, len(items))
+
+		for i, item := range items {
+			var itemTag *Tag
+
+			if itemTag, err = plainToTag(item, itemSchema, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return
+			}
+
+			l = append(l, itemTag)
+		}
+
+		return l, nil
+	case TypeCompound:
+		m, ok := v.(map[string]any)
+
+		if !ok {
+			return nil, mismatch()
+		}
+
+		c := make(Compound, len(m))
+
+		for name, child := range m {
+			// null has no NBT equivalent, the entry is left out
+			if child == nil {
+				continue
+			}
+
+			childPath := name
+
+			if path != "" {
+				childPath = path + "." + name
+			}
+
+			var childTag *Tag
+
+			if childTag, err = plainToTag(child, s.field(name), childPath); err != nil {
+				return
+			}
+
+			childTag.Name = []byte(name)
+			c[name] = childTag
+		}
+
+		return c, nil
+	default:
+		return nil, mismatch()
+	}
+}
+
+func plainInt(i int64, typ int, mismatch func() error) (any, error) {
+	switch typ {
+	case TypeByte:
+		if i < math.MinInt8 || i > math.MaxInt8 {
+			return nil, mismatch()
+		}
+
+		return int8(i), nil
+	case TypeShort:
+		if i < math.MinInt16 || i > math.MaxInt16 {
+			return nil, mismatch()
+		}
+
+		return int16(i), nil
+	case TypeInt:
+		if i < math.MinInt32 || i > math.MaxInt32 {
+			return nil, mismatch()
+		}
+
+		return int32(i), nil
+	default:
+		return i, nil
+	}
+}
+
+func plainArray(v any, typ int, path string) (res any, err error) {
+	items, ok := v.([]any)
+
+	if !ok {
+		return nil, fmt.Errorf("nbt: %s: cannot convert %v to tag type %d", pathOrRoot(path), v, typ)
+	}
+
+	itemType := map[int]int{TypeByteArray: TypeByte, TypeIntArray: TypeInt, TypeLongArray: TypeLong}[typ]
+
+	values := make([]any, len(items))
+
+	for i, item := range items {
+		if values[i], err = plainValue(item, itemType, nil, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return
+		}
+	}
+
+	switch typ {
+	case TypeByteArray:
+		bs := make([]byte, len(values))
+
+		for i, b := range values {
+			bs[i] = byte(b.(int8))
+		}
+
+		return bs, nil
+	case TypeIntArray:
+		is := make([]int32, len(values))
+
+		for i, n := range values {
+			is[i] = n.(int32)
+		}
+
+		return is, nil
+	default:
+		ls := make([]int64, len(values))
+
+		for i, n := range values {
+			ls[i] = n.(int64)
+		}
+
+		return ls, nil
+	}
+}
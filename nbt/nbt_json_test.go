@@ -0,0 +1,217 @@
+package nbt
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	tag := allTypesTag()
+
+	bs, err := json.Marshal(tag)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(bs), `"value":"-1234567890123456789"`) {
+		t.Fatalf("expected long as string, got %s", bs)
+	}
+
+	decoded := &Tag{}
+
+	if err := json.Unmarshal(bs, decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded, tag) {
+		t.Fatalf("expected %v, got %v", tag, decoded)
+	}
+}
+
+func TestJSONRoundTripBigTest(t *testing.T) {
+	f, err := os.Open("../testdata/bigtest.nbt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	tag := &Tag{}
+
+	if err := NewDecoder(f).Decode(tag); err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := json.Marshal(tag)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := &Tag{}
+
+	if err := json.Unmarshal(bs, decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded, tag) {
+		t.Fatalf("expected %v, got %v", tag, decoded)
+	}
+}
+
+func TestJSONNonFinite(t *testing.T) {
+	l := List{
+		{Type: TypeDouble, Value: math.NaN()},
+		{Type: TypeDouble, Value: math.Inf(-1)},
+		{Type: TypeFloat, Value: float32(math.Inf(1))},
+	}
+
+	bs, err := json.Marshal(l)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := List{}
+
+	if err := json.Unmarshal(bs, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !math.IsNaN(decoded[0].Value.(float64)) {
+		t.Fatalf("expected NaN, got %v", decoded[0].Value)
+	}
+
+	if !math.IsInf(decoded[1].Value.(float64), -1) {
+		t.Fatalf("expected -Inf, got %v", decoded[1].Value)
+	}
+
+	if !math.IsInf(float64(decoded[2].Value.(float32)), 1) {
+		t.Fatalf("expected +Inf, got %v", decoded[2].Value)
+	}
+}
+
+func TestJSONCompound(t *testing.T) {
+	c := Compound{}
+
+	if err := json.Unmarshal([]byte(`{"x": {"type": 4, "value": 5}}`), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(c["x"].Name) != "x" || c["x"].Value != int64(5) {
+		t.Fatalf("unexpected %v", c["x"])
+	}
+
+	if err := json.Unmarshal([]byte(`{"x": {"type": 1, "value": 300}}`), &c); err == nil {
+		t.Fatal("expected error for byte out of range")
+	}
+}
+
+func TestPlainJSON(t *testing.T) {
+	tag, err := ParseSNBT(`{name: "Steve", level: 3b, pos: [1.5d, 64.0d, -2.5d], bytes: [B; 1b, -1b], longs: [L; 5L], nan: NaNd}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "NaNd" isn't a number, the parser reads it as a string
+	tag.Value.(Compound)["nan"].Type = TypeDouble
+	tag.Value.(Compound)["nan"].Value = math.NaN()
+
+	bs, err := MarshalPlainJSON(tag)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"bytes":[1,-1],"level":3,"longs":[5],"name":"Steve","nan":null,"pos":[1.5,64,-2.5]}`
+
+	if string(bs) != expected {
+		t.Fatalf("expected %s, got %s", expected, bs)
+	}
+}
+
+func TestUnmarshalPlainJSONInferred(t *testing.T) {
+	tag, err := UnmarshalPlainJSON([]byte(`{"a": 1, "b": 3000000000, "c": 1.5, "d": true, "e": "x", "f": [1, 2.5], "g": [{"h": []}], "i": null}`), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := ParseSNBT(`{a: 1, b: 3000000000L, c: 1.5d, d: 1b, e: "x", f: [1.0d, 2.5d], g: [{h: []}]}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(tag, expected) {
+		t.Fatalf("expected %v, got %v", expected, tag)
+	}
+
+	if _, err := UnmarshalPlainJSON([]byte(`{"a": [1, "x"]}`), nil); err == nil {
+		t.Fatal("expected error for mixed list")
+	}
+}
+
+func TestUnmarshalPlainJSONHints(t *testing.T) {
+	type player struct {
+		Level     int8               `nbt:"level"`
+		Health    float32            `nbt:"health"`
+		Pos       []float64          `nbt:"pos"`
+		Inventory []map[string]int16 `nbt:"inventory"`
+		Flags     []byte             `nbt:"flags"`
+		OnGround  bool               `nbt:"onGround"`
+	}
+
+	input := []byte(`{"level": 3, "health": 20, "pos": [1, 2, 3], "inventory": [{"slot": 1}], "flags": [1, -1], "onGround": true, "extra": 7}`)
+
+	tag, err := UnmarshalPlainJSON(input, &player{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := ParseSNBT(`{level: 3b, health: 20f, pos: [1d, 2d, 3d], inventory: [{slot: 1s}], flags: [B; 1b, -1b], onGround: 1b, extra: 7}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(tag, expected) {
+		t.Fatalf("expected %v, got %v", expected, tag)
+	}
+
+	schema, err := ParseSNBT(`{ids: [I; 0], count: 0L}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err = UnmarshalPlainJSON([]byte(`{"ids": [1, 2], "count": 5}`), schema)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err = ParseSNBT(`{ids: [I; 1, 2], count: 5L}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(tag, expected) {
+		t.Fatalf("expected %v, got %v", expected, tag)
+	}
+
+	if _, err := UnmarshalPlainJSON([]byte(`{"level": 300}`), &player{}); err == nil || !strings.Contains(err.Error(), "level") {
+		t.Fatalf("expected error mentioning level, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package nbt
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Java Edition encodes strings in Java's Modified UTF-8: NUL is written as the two bytes 0xC0 0x80 and
+// supplementary characters are written as two 3 byte encoded UTF-16 surrogates instead of 4 bytes.
+
+func isASCIIWithoutNul(bs []byte) bool {
+	for _, b := range bs {
+		if b == 0 || b >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// decodeMUTF8Unit decodes one 1 to 3 byte sequence into a UTF-16 code unit. size is 0 if the sequence is invalid.
+func decodeMUTF8Unit(bs []byte) (unit rune, size int) {
+	b := bs[0]
+
+	switch {
+	case b != 0 && b < 0x80:
+		return rune(b), 1
+	case b&0xe0 == 0xc0:
+		if len(bs) < 2 || bs[1]&0xc0 != 0x80 {
+			return
+		}
+
+		unit = rune(b&0x1f)<<6 | rune(bs[1]&0x3f)
+
+		// overlong encodings are only allowed for NUL
+		if unit != 0 && unit < 0x80 {
+			return 0, 0
+		}
+
+		return unit, 2
+	case b&0xf0 == 0xe0:
+		if len(bs) < 3 || bs[1]&0xc0 != 0x80 || bs[2]&0xc0 != 0x80 {
+			return
+		}
+
+		unit = rune(b&0x0f)<<12 | rune(bs[1]&0x3f)<<6 | rune(bs[2]&0x3f)
+
+		if unit < 0x800 {
+			return 0, 0
+		}
+
+		return unit, 3
+	default:
+		return
+	}
+}
+
+// decodeMUTF8 converts Modified UTF-8 to UTF-8. Invalid sequences are rejected if strict is set and copied as they
+// are otherwise.
+func decodeMUTF8(bs []byte, strict bool) (res []byte, err error) {
+	if isASCIIWithoutNul(bs) {
+		return bs, nil
+	}
+
+	res = make([]byte, 0, len(bs))
+
+	for i := 0; i < len(bs); {
+		unit, size := decodeMUTF8Unit(bs[i:])
+
+		if size > 0 && utf16.IsSurrogate(unit) {
+			// a high surrogate has to be followed by a low surrogate
+			if unit < 0xdc00 && i+size < len(bs) {
+				low, lowSize := decodeMUTF8Unit(bs[i+size:])
+
+				if lowSize > 0 && low >= 0xdc00 && low <= 0xdfff {
+					res = utf8.AppendRune(res, utf16.DecodeRune(unit, low))
+					i += size + lowSize
+
+					continue
+				}
+			}
+
+			size = 0
+		}
+
+		if size == 0 {
+			if strict {
+				err = fmt.Errorf("nbt: invalid modified UTF-8 at byte %d", i)
+				return
+			}
+
+			res = append(res, bs[i])
+			i++
+
+			continue
+		}
+
+		res = utf8.AppendRune(res, unit)
+		i += size
+	}
+
+	return
+}
+
+// encodeMUTF8 converts UTF-8 to Modified UTF-8. Invalid UTF-8 is rejected if strict is set and copied as it is
+// otherwise.
+func encodeMUTF8(bs []byte, strict bool) (res []byte, err error) {
+	if isASCIIWithoutNul(bs) {
+		return bs, nil
+	}
+
+	res = make([]byte, 0, len(bs)+len(bs)/2)
+
+	for i := 0; i < len(bs); {
+		r, size := utf8.DecodeRune(bs[i:])
+
+		if r == utf8.RuneError && size <= 1 {
+			if strict {
+				err = fmt.Errorf("nbt: invalid UTF-8 at byte %d", i)
+				return
+			}
+
+			res = append(res, bs[i])
+			i++
+
+			continue
+		}
+
+		i += size
+
+		switch {
+		case r == 0:
+			res = append(res, 0xc0, 0x80)
+		case r < 0x80:
+			res = append(res, byte(r))
+		case r < 0x10000:
+			res = utf8.AppendRune(res, r)
+		default:
+			high, low := utf16.EncodeRune(r)
+
+			res = appendMUTF8Unit(res, high)
+			res = appendMUTF8Unit(res, low)
+		}
+	}
+
+	return
+}
+
+// appendMUTF8Unit appends the 3 byte encoding of a surrogate code unit, which utf8.AppendRune refuses to encode.
+func appendMUTF8Unit(bs []byte, unit rune) []byte {
+	return append(bs, 0xe0|byte(unit>>12), 0x80|byte(unit>>6)&0x3f, 0x80|byte(unit)&0x3f)
+}
+
+// decodeString converts a string read from the wire to UTF-8.
+func (o *options) decodeString(bs []byte) ([]byte, error) {
+	if o.modifiedUTF8 {
+		return decodeMUTF8(bs, o.strictStrings)
+	}
+
+	if o.strictStrings && !utf8.Valid(bs) {
+		return nil, fmt.Errorf("nbt: invalid UTF-8")
+	}
+
+	return bs, nil
+}
+
+// encodeString converts a UTF-8 string to the representation written to the wire.
+func (o *options) encodeString(bs []byte) ([]byte, error) {
+	if o.modifiedUTF8 {
+		return encodeMUTF8(bs, o.strictStrings)
+	}
+
+	if o.strictStrings && !utf8.Valid(bs) {
+		return nil, fmt.Errorf("nbt: invalid UTF-8")
+	}
+
+	return bs, nil
+}
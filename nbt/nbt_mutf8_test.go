@@ -0,0 +1,109 @@
+package nbt
+
+import (
+	"bytes"
+	"testing"
+)
+
+var mutf8Tests = []struct {
+	utf8  string
+	mutf8 []byte
+}{
+	{"hello", []byte("hello")},
+	{"ÅÄÖ", []byte("ÅÄÖ")},
+	{"a\x00b", []byte{'a', 0xc0, 0x80, 'b'}},
+	{"€", []byte{0xe2, 0x82, 0xac}},
+	{"😀", []byte{0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80}},
+}
+
+func TestModifiedUTF8(t *testing.T) {
+	for _, test := range mutf8Tests {
+		encoded, err := encodeMUTF8([]byte(test.utf8), true)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(encoded, test.mutf8) {
+			t.Fatalf("expected %x, got %x", test.mutf8, encoded)
+		}
+
+		decoded, err := decodeMUTF8(test.mutf8, true)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(decoded) != test.utf8 {
+			t.Fatalf("expected %q, got %q", test.utf8, decoded)
+		}
+	}
+}
+
+func TestModifiedUTF8Invalid(t *testing.T) {
+	invalid := [][]byte{
+		{'a', 0x00},
+		{0xf0, 0x9f, 0x98, 0x80},
+		{0xed, 0xa0, 0xbd},
+		{0xc1, 0x81},
+		{0xe2, 0x82},
+	}
+
+	for _, bs := range invalid {
+		if _, err := decodeMUTF8(bs, true); err == nil {
+			t.Fatalf("expected error for %x", bs)
+		}
+
+		decoded, err := decodeMUTF8(bs, false)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(decoded, bs) {
+			t.Fatalf("expected %x to pass through, got %x", bs, decoded)
+		}
+	}
+
+	if _, err := encodeMUTF8([]byte{'a', 0xff}, true); err == nil {
+		t.Fatal("expected error for invalid UTF-8")
+	}
+}
+
+func TestModifiedUTF8RoundTrip(t *testing.T) {
+	tag := &Tag{
+		Type:  TypeString,
+		Name:  []byte("name\x00"),
+		Value: "emoji 😀 and\x00nul",
+	}
+
+	bs, err := Marshal(tag)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(bs, []byte{0xf0}) || !bytes.Contains(bs, []byte{0xc0, 0x80, 'n', 'u', 'l'}) {
+		t.Fatalf("expected modified UTF-8, got %x", bs)
+	}
+
+	decoded := Tag{}
+
+	if err := Unmarshal(bs, &decoded, WithStrictStrings()); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Value != tag.Value || string(decoded.Name) != string(tag.Name) {
+		t.Fatalf("expected %v, got %v", tag, &decoded)
+	}
+
+	bs, err = Marshal(tag, WithDialect(DialectBedrock))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(bs, []byte("😀")) {
+		t.Fatalf("expected plain UTF-8, got %x", bs)
+	}
+}
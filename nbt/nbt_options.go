@@ -0,0 +1,141 @@
+package nbt
+
+import "encoding/binary"
+
+type options struct {
+	compression      Compression
+	compressionLevel int
+	byteOrder        binary.ByteOrder
+	sortKeys         bool
+	bedrockHeader    bool
+	bedrockVersion   int32
+	varint           bool
+	namelessRoot     bool
+	modifiedUTF8     bool
+	strictStrings    bool
+}
+
+// Dialect is one of the binary NBT variants used by the different Minecraft editions.
+type Dialect int
+
+const (
+	// DialectJava is big endian NBT with fixed width numbers and Modified UTF-8 strings, used by Java Edition files
+	// and the network protocol.
+	DialectJava Dialect = iota
+	// DialectBedrock is little endian NBT with fixed width numbers and UTF-8 strings, used by Bedrock Edition files.
+	DialectBedrock
+	// DialectBedrockNetwork is little endian NBT used by the Bedrock Edition network protocol. Int and long values
+	// as well as list and array lengths are zig-zag encoded varints, string lengths are unsigned varints.
+	DialectBedrockNetwork
+)
+
+func (o *options) setDialect(d Dialect) {
+	switch d {
+	case DialectBedrock:
+		o.byteOrder = binary.LittleEndian
+		o.varint = false
+		o.modifiedUTF8 = false
+	case DialectBedrockNetwork:
+		o.byteOrder = binary.LittleEndian
+		o.varint = true
+		o.modifiedUTF8 = false
+	default:
+		o.byteOrder = binary.BigEndian
+		o.varint = false
+		o.modifiedUTF8 = true
+	}
+}
+
+// setByteOrder sets the byte order along with the string encoding used by the edition with that byte order.
+func (o *options) setByteOrder(order binary.ByteOrder) {
+	o.byteOrder = order
+	o.modifiedUTF8 = order != binary.LittleEndian
+}
+
+// BedrockHeader is the 8 byte header in front of the NBT data in Bedrock Edition level.dat files.
+// Both fields are always little endian.
+type BedrockHeader struct {
+	// Version is the storage version of the file.
+	Version int32
+	// Length is the length of the NBT data following the header.
+	Length int32
+}
+
+func defaultOptions() options {
+	return options{
+		compression:      CompressionNone,
+		compressionLevel: DefaultCompressionLevel,
+		byteOrder:        binary.BigEndian,
+		modifiedUTF8:     true,
+	}
+}
+
+func (o *options) apply(opts []Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// Option configures Marshal, MarshalWriter, Unmarshal and UnmarshalReader. Options that only make sense in one
+// direction are ignored in the other.
+type Option func(o *options)
+
+// WithCompression compresses the output with c.
+func WithCompression(c Compression) Option {
+	return func(o *options) {
+		o.compression = c
+	}
+}
+
+// WithCompressionLevel sets the level used by WithCompression, see compress/flate for valid values.
+func WithCompressionLevel(level int) Option {
+	return func(o *options) {
+		o.compressionLevel = level
+	}
+}
+
+// WithByteOrder sets the byte order of numbers and lengths. Java Edition uses binary.BigEndian, which is the default.
+// Strings follow the byte order: Modified UTF-8 for big endian, UTF-8 for little endian as used by Bedrock Edition.
+// WithDialect selects all of these at once.
+func WithByteOrder(order binary.ByteOrder) Option {
+	return func(o *options) {
+		o.setByteOrder(order)
+	}
+}
+
+// WithSortedKeys writes compound entries sorted by name instead of in map order, making the output deterministic.
+func WithSortedKeys() Option {
+	return func(o *options) {
+		o.sortKeys = true
+	}
+}
+
+// WithBedrockHeader reads and writes a BedrockHeader in front of the root tag. When writing, the header carries version.
+func WithBedrockHeader(version int32) Option {
+	return func(o *options) {
+		o.bedrockHeader = true
+		o.bedrockVersion = version
+	}
+}
+
+// WithDialect selects the binary NBT variant to read and write, see Dialect.
+func WithDialect(d Dialect) Option {
+	return func(o *options) {
+		o.setDialect(d)
+	}
+}
+
+// WithNamelessRoot reads and writes the root tag without a name, as done by the Java Edition network protocol
+// since 1.20.2 (protocol 764). The root tag is unmarshalled as if its name was empty.
+func WithNamelessRoot() Option {
+	return func(o *options) {
+		o.namelessRoot = true
+	}
+}
+
+// WithStrictStrings rejects strings that are not valid (Modified) UTF-8 instead of passing their bytes through.
+func WithStrictStrings() Option {
+	return func(o *options) {
+		o.strictStrings = true
+	}
+}
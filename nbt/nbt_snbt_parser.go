@@ -0,0 +1,522 @@
+package nbt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError describes where and why ParseSNBT failed. Line and Column are 1-based, Column counts runes.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("nbt: snbt syntax error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+var (
+	snbtIntPattern    = regexp.MustCompile(`^[-+]?(?:0|[1-9][0-9]*)$`)
+	snbtFloatPattern  = regexp.MustCompile(`^[-+]?(?:[0-9]+[.]?|[0-9]*[.][0-9]+)(?:[eE][-+]?[0-9]+)?$`)
+	snbtDoublePattern = regexp.MustCompile(`^[-+]?(?:[0-9]+[.]|[0-9]*[.][0-9]+)(?:[eE][-+]?[0-9]+)?$|^[-+]?[0-9]+[eE][-+]?[0-9]+$`)
+)
+
+type snbtParser struct {
+	s   string
+	pos int
+}
+
+// ParseSNBT parses stringified NBT as used in commands, e.g. {Count:1b,id:"minecraft:stone"}. The resulting tags
+// carry the same Go types as tags read by the Decoder. The root tag has no name.
+func ParseSNBT(s string) (tag *Tag, err error) {
+	p := &snbtParser{s: s}
+
+	if tag, err = p.parseValue(); err != nil {
+		return
+	}
+
+	p.skipWhitespace()
+
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected trailing data")
+	}
+
+	return
+}
+
+func (p *snbtParser) errorf(format string, args ...any) error {
+	line, column := 1, 1
+
+	for _, r := range p.s[:min(p.pos, len(p.s))] {
+		if r == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+
+	return &SyntaxError{
+		Line:   line,
+		Column: column,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *snbtParser) skipWhitespace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) != -1 {
+		p.pos++
+	}
+}
+
+func (p *snbtParser) peek() (b byte, ok bool) {
+	p.skipWhitespace()
+
+	if p.pos >= len(p.s) {
+		return
+	}
+
+	return p.s[p.pos], true
+}
+
+func (p *snbtParser) expect(b byte) error {
+	if c, ok := p.peek(); !ok {
+		return p.errorf("expected '%c', got end of input", b)
+	} else if c != b {
+		return p.errorf("expected '%c', got '%c'", b, c)
+	}
+
+	p.pos++
+
+	return nil
+}
+
+func isUnquotedChar(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' ||
+		b == '_' || b == '-' || b == '.' || b == '+'
+}
+
+func (p *snbtParser) readUnquoted() string {
+	start := p.pos
+
+	for p.pos < len(p.s) && isUnquotedChar(p.s[p.pos]) {
+		p.pos++
+	}
+
+	return p.s[start:p.pos]
+}
+
+func (p *snbtParser) readQuoted() (s string, err error) {
+	quote := p.s[p.pos]
+
+	p.pos++
+
+	sb := strings.Builder{}
+
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+
+		switch c {
+		case quote:
+			p.pos++
+			return sb.String(), nil
+		case '\\':
+			if err = p.readEscape(&sb); err != nil {
+				return
+			}
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+func (p *snbtParser) readEscape(sb *strings.Builder) (err error) {
+	if p.pos+1 >= len(p.s) {
+		p.pos++
+		return p.errorf("unterminated escape sequence")
+	}
+
+	c := p.s[p.pos+1]
+
+	var digits int
+
+	switch c {
+	case '\\', '"', '\'':
+		sb.WriteByte(c)
+	case 'n':
+		sb.WriteByte('\n')
+	case 't':
+		sb.WriteByte('\t')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 's':
+		sb.WriteByte(' ')
+	case 'x':
+		digits = 2
+	case 'u':
+		digits = 4
+	case 'U':
+		digits = 8
+	default:
+		return p.errorf("invalid escape sequence '\\%c'", c)
+	}
+
+	if digits == 0 {
+		p.pos += 2
+		return
+	}
+
+	start := p.pos + 2
+
+	if start+digits > len(p.s) {
+		return p.errorf("invalid escape sequence")
+	}
+
+	v, err := strconv.ParseUint(p.s[start:start+digits], 16, 32)
+
+	if err != nil || !utf8.ValidRune(rune(v)) {
+		return p.errorf("invalid escape sequence '%s'", p.s[p.pos:start+digits])
+	}
+
+	sb.WriteRune(rune(v))
+	p.pos = start + digits
+
+	return nil
+}
+
+func (p *snbtParser) readKey() (key string, err error) {
+	c, ok := p.peek()
+
+	if !ok {
+		return "", p.errorf("expected key, got end of input")
+	}
+
+	if c == '"' || c == '\'' {
+		return p.readQuoted()
+	}
+
+	if key = p.readUnquoted(); key == "" {
+		return "", p.errorf("expected key, got '%c'", c)
+	}
+
+	return
+}
+
+func (p *snbtParser) parseValue() (tag *Tag, err error) {
+	c, ok := p.peek()
+
+	if !ok {
+		return nil, p.errorf("expected value, got end of input")
+	}
+
+	switch c {
+	case '{':
+		return p.parseCompound()
+	case '[':
+		if p.pos+2 < len(p.s) && p.s[p.pos+2] == ';' {
+			return p.parseArray()
+		}
+
+		return p.parseList()
+	case '"', '\'':
+		var s string
+
+		if s, err = p.readQuoted(); err != nil {
+			return
+		}
+
+		return &Tag{Type: TypeString, Value: s}, nil
+	}
+
+	start := p.pos
+	s := p.readUnquoted()
+
+	if s == "" {
+		return nil, p.errorf("unexpected '%c'", c)
+	}
+
+	if tag, err = parseSNBTScalar(s); err != nil {
+		p.pos = start
+		return nil, p.errorf("%s", err)
+	}
+
+	return
+}
+
+// parseSNBTScalar interprets an unquoted value as a number or boolean, falling back to a string.
+func parseSNBTScalar(s string) (tag *Tag, err error) {
+	switch strings.ToLower(s) {
+	case "true":
+		return &Tag{Type: TypeByte, Value: int8(1)}, nil
+	case "false":
+		return &Tag{Type: TypeByte, Value: int8(0)}, nil
+	}
+
+	last := s[len(s)-1]
+	body := s[:len(s)-1]
+
+	switch {
+	case snbtIntPattern.MatchString(s):
+		tag = &Tag{Type: TypeInt}
+		tag.Value, err = parseSNBTInt[int32](s, 32)
+	case (last == 'b' || last == 'B') && snbtIntPattern.MatchString(body):
+		tag = &Tag{Type: TypeByte}
+		tag.Value, err = parseSNBTInt[int8](body, 8)
+	case (last == 's' || last == 'S') && snbtIntPattern.MatchString(body):
+		tag = &Tag{Type: TypeShort}
+		tag.Value, err = parseSNBTInt[int16](body, 16)
+	case (last == 'l' || last == 'L') && snbtIntPattern.MatchString(body):
+		tag = &Tag{Type: TypeLong}
+		tag.Value, err = parseSNBTInt[int64](body, 64)
+	case (last == 'f' || last == 'F') && snbtFloatPattern.MatchString(body):
+		var f float64
+
+		f, err = strconv.ParseFloat(body, 32)
+		tag = &Tag{Type: TypeFloat, Value: float32(f)}
+	case (last == 'd' || last == 'D') && snbtFloatPattern.MatchString(body):
+		var f float64
+
+		f, err = strconv.ParseFloat(body, 64)
+		tag = &Tag{Type: TypeDouble, Value: f}
+	case snbtDoublePattern.MatchString(s):
+		var f float64
+
+		f, err = strconv.ParseFloat(s, 64)
+		tag = &Tag{Type: TypeDouble, Value: f}
+	default:
+		return &Tag{Type: TypeString, Value: s}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("number out of range: %s", s)
+	}
+
+	return
+}
+
+func parseSNBTInt[T interface{ int8 | int16 | int32 | int64 }](s string, bitSize int) (v T, err error) {
+	i, err := strconv.ParseInt(s, 10, bitSize)
+
+	return T(i), err
+}
+
+func (p *snbtParser) parseCompound() (tag *Tag, err error) {
+	p.pos++
+
+	c := Compound{}
+
+	if b, ok := p.peek(); ok && b == '}' {
+		p.pos++
+		return &Tag{Type: TypeCompound, Value: c}, nil
+	}
+
+	for {
+		var key string
+
+		if key, err = p.readKey(); err != nil {
+			return
+		}
+
+		if err = p.expect(':'); err != nil {
+			return
+		}
+
+		var child *Tag
+
+		if child, err = p.parseValue(); err != nil {
+			return
+		}
+
+		child.Name = []byte(key)
+		c[key] = child
+
+		b, ok := p.peek()
+
+		if !ok {
+			return nil, p.errorf("expected ',' or '}', got end of input")
+		}
+
+		p.pos++
+
+		if b == '}' {
+			return &Tag{Type: TypeCompound, Value: c}, nil
+		} else if b != ',' {
+			p.pos--
+			return nil, p.errorf("expected ',' or '}', got '%c'", b)
+		}
+	}
+}
+
+func (p *snbtParser) parseList() (tag *Tag, err error) {
+	p.pos++
+
+	l := List{}
+
+	if b, ok := p.peek(); ok && b == ']' {
+		p.pos++
+		return &Tag{Type: TypeList, Value: l}, nil
+	}
+
+	for {
+		start := p.pos
+
+		var item *Tag
+
+		if item, err = p.parseValue(); err != nil {
+			return
+		}
+
+		if len(l) > 0 && item.Type != l[0].Type {
+			p.pos = start
+			p.skipWhitespace()
+
+			return nil, p.errorf("list items have different types")
+		}
+
+		l = append(l, item)
+
+		b, ok := p.peek()
+
+		if !ok {
+			return nil, p.errorf("expected ',' or ']', got end of input")
+		}
+
+		p.pos++
+
+		if b == ']' {
+			return &Tag{Type: TypeList, Value: l}, nil
+		} else if b != ',' {
+			p.pos--
+			return nil, p.errorf("expected ',' or ']', got '%c'", b)
+		}
+	}
+}
+
+func (p *snbtParser) parseArray() (tag *Tag, err error) {
+	arrayType := p.s[p.pos+1]
+
+	p.pos += 3
+
+	var values []int64
+
+	var bitSize int
+
+	switch arrayType {
+	case 'B':
+		tag = &Tag{Type: TypeByteArray}
+		bitSize = 8
+	case 'I':
+		tag = &Tag{Type: TypeIntArray}
+		bitSize = 32
+	case 'L':
+		tag = &Tag{Type: TypeLongArray}
+		bitSize = 64
+	default:
+		p.pos -= 2
+		return nil, p.errorf("invalid array type '%c'", arrayType)
+	}
+
+	if b, ok := p.peek(); !ok || b != ']' {
+		for {
+			if _, ok := p.peek(); !ok {
+				return nil, p.errorf("expected array value, got end of input")
+			}
+
+			start := p.pos
+
+			var v int64
+
+			if v, err = parseSNBTArrayValue(p.readUnquoted(), arrayType, bitSize); err != nil {
+				p.pos = start
+				return nil, p.errorf("%s", err)
+			}
+
+			values = append(values, v)
+
+			b, ok := p.peek()
+
+			if !ok {
+				return nil, p.errorf("expected ',' or ']', got end of input")
+			}
+
+			if b == ']' {
+				break
+			} else if b != ',' {
+				return nil, p.errorf("expected ',' or ']', got '%c'", b)
+			}
+
+			p.pos++
+		}
+	}
+
+	p.pos++
+
+	switch tag.Type {
+	case TypeByteArray:
+		bs := make([]byte, len(values))
+
+		for i, v := range values {
+			bs[i] = byte(v)
+		}
+
+		tag.Value = bs
+	case TypeIntArray:
+		is := make([]int32, len(values))
+
+		for i, v := range values {
+			is[i] = int32(v)
+		}
+
+		tag.Value = is
+	default:
+		if values == nil {
+			values = []int64{}
+		}
+
+		tag.Value = values
+	}
+
+	return
+}
+
+// parseSNBTArrayValue parses one entry of a typed array. Entries may carry the suffix matching the array type.
+func parseSNBTArrayValue(s string, arrayType byte, bitSize int) (v int64, err error) {
+	if s == "" {
+		return 0, fmt.Errorf("expected array value")
+	}
+
+	last := s[len(s)-1]
+
+	if (arrayType == 'B' && (last == 'b' || last == 'B')) || (arrayType == 'L' && (last == 'l' || last == 'L')) {
+		s = s[:len(s)-1]
+	}
+
+	if arrayType == 'B' && (strings.EqualFold(s, "true") || strings.EqualFold(s, "false")) {
+		if strings.EqualFold(s, "true") {
+			return 1, nil
+		}
+
+		return 0, nil
+	}
+
+	if !snbtIntPattern.MatchString(s) {
+		return 0, fmt.Errorf("invalid %c array value: %s", arrayType, s)
+	}
+
+	if v, err = strconv.ParseInt(s, 10, bitSize); err != nil {
+		return 0, fmt.Errorf("number out of range: %s", s)
+	}
+
+	return
+}
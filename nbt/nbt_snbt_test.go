@@ -0,0 +1,224 @@
+package nbt
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseSNBT(t *testing.T) {
+	tag, err := ParseSNBT(`{
+		byte: 12b, short: -3s, int: 42, long: 9223372036854775807L,
+		float: 0.5f, double: 1.25d, implicitDouble: 2.5, exp: 1e3,
+		bool: true, off: false,
+		"quoted key": "say \"hi\"", 'single': 'it\'s',
+		unquoted: minecraft_stone,
+		escaped: "a\nbä",
+		list: [1s, 2s, 3s],
+		empty: [],
+		nested: {list: [{a: 1}, {a: 2}]},
+		bytes: [B; 1b, -2b, 3], ints: [I; 1, -2, 3], longs: [L; 1L, -2l, 3],
+		emptyInts: [I;]
+	}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{
+		"byte":           int8(12),
+		"short":          int16(-3),
+		"int":            int32(42),
+		"long":           int64(9223372036854775807),
+		"float":          float32(0.5),
+		"double":         1.25,
+		"implicitDouble": 2.5,
+		"exp":            1000.0,
+		"bool":           int8(1),
+		"off":            int8(0),
+		"quoted key":     `say "hi"`,
+		"single":         "it's",
+		"unquoted":       "minecraft_stone",
+		"escaped":        "a\nbä",
+		"bytes":          []byte{1, 0xfe, 3},
+		"ints":           []int32{1, -2, 3},
+		"longs":          []int64{1, -2, 3},
+		"emptyInts":      []int32{},
+	}
+
+	for name, value := range expected {
+		child, ok := tag.Find(name)
+
+		if !ok {
+			t.Fatalf("%s not found", name)
+		}
+
+		if string(child.Name) != name {
+			t.Fatalf("expected name %s, got %s", name, child.Name)
+		}
+
+		if !reflect.DeepEqual(child.Value, value) {
+			t.Fatalf("%s: expected %#v, got %#v", name, value, child.Value)
+		}
+	}
+
+	list, _ := tag.Find("list")
+
+	if list.Type != TypeList || len(list.Value.(List)) != 3 || list.Value.(List)[2].Value != int16(3) {
+		t.Fatalf("unexpected list %v", list)
+	}
+
+	nested, _ := tag.Find("nested")
+	nestedList, _ := nested.Find("list")
+	a, _ := nestedList.Value.(List)[1].Find("a")
+
+	if a.Value != int32(2) {
+		t.Fatalf("expected 2, got %v", a.Value)
+	}
+}
+
+func TestParseSNBTErrors(t *testing.T) {
+	tests := []struct {
+		snbt   string
+		line   int
+		column int
+	}{
+		{`{a: 1`, 1, 6},
+		{`{a 1}`, 1, 4},
+		{"{\n  a: [1, 2b]\n}", 2, 10},
+		{"{\n  a: 300b\n}", 2, 6},
+		{`[I; 1, x]`, 1, 8},
+		{`{a: "open}`, 1, 11},
+		{`{a: 1} b`, 1, 8},
+		{`{a: "\q"}`, 1, 6},
+	}
+
+	for _, test := range tests {
+		_, err := ParseSNBT(test.snbt)
+
+		var syntaxErr *SyntaxError
+
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("%q: expected SyntaxError, got %v", test.snbt, err)
+		}
+
+		if syntaxErr.Line != test.line || syntaxErr.Column != test.column {
+			t.Fatalf("%q: expected %d:%d, got %d:%d (%s)", test.snbt, test.line, test.column, syntaxErr.Line, syntaxErr.Column, err)
+		}
+	}
+}
+
+func TestSNBT(t *testing.T) {
+	tag, err := ParseSNBT(`{b: [B; 1b, -1b], name: 'say "hi"', z: [{x: 1.5f}, {}], "a b": [I; 1, 2], l: 3L, s: [], d: 2.0d}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"a b":[I;1,2],b:[B;1b,-1b],d:2d,l:3L,name:'say "hi"',s:[],z:[{x:1.5f},{}]}`
+
+	if s := snbt(t, tag); s != expected {
+		t.Fatalf("expected %s, got %s", expected, s)
+	}
+
+	expected = `{
+  "a b": [I; 1, 2],
+  b: [B; 1b, -1b],
+  d: 2d,
+  l: 3L,
+  name: 'say "hi"',
+  s: [],
+  z: [
+    {
+      x: 1.5f
+    },
+    {}
+  ]
+}`
+
+	pretty, err := (&SNBTFormatter{Indent: "  "}).Format(tag)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pretty != expected {
+		t.Fatalf("expected %s, got %s", expected, pretty)
+	}
+}
+
+func TestSNBTRoundTrip(t *testing.T) {
+	tag := allTypesTag()
+
+	tag.Name = nil
+
+	tag.Value.(Compound)["quotes"] = &Tag{Type: TypeString, Name: []byte("quotes"), Value: `it's "quoted" \ `}
+
+	for _, f := range []*SNBTFormatter{{}, {Indent: "\t"}} {
+		s, err := f.Format(tag)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := ParseSNBT(s)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(parsed, tag) {
+			t.Fatalf("expected %v, got %v", tag, parsed)
+		}
+	}
+}
+
+func TestSNBTNonFinite(t *testing.T) {
+	tag := &Tag{Type: TypeCompound, Value: Compound{
+		"nan":  {Type: TypeDouble, Name: []byte("nan"), Value: math.NaN()},
+		"inf":  {Type: TypeFloat, Name: []byte("inf"), Value: float32(math.Inf(1))},
+		"ninf": {Type: TypeList, Name: []byte("ninf"), Value: List{{Type: TypeDouble, Value: math.Inf(-1)}}},
+	}}
+
+	if s, err := tag.SNBT(); err == nil {
+		t.Fatalf("expected error for non-finite floats, got %s", s)
+	}
+
+	s, err := (&SNBTFormatter{NonFinite: true}).Format(tag)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s != "{inf:Infinityf,nan:NaNd,ninf:[-Infinityd]}" {
+		t.Fatalf("unexpected SNBT %s", s)
+	}
+
+	// like the game, the parser reads these as strings
+	res, err := ParseSNBT(s)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, _ := res.Find("nan"); v.Type != TypeString || v.Value != "NaNd" {
+		t.Fatalf("expected string NaNd, got %#v", v)
+	}
+
+	if v, _ := res.Find("inf"); v.Type != TypeString || v.Value != "Infinityf" {
+		t.Fatalf("expected string Infinityf, got %#v", v)
+	}
+}
+
+func snbt(t *testing.T, tag *Tag) string {
+	t.Helper()
+
+	s, err := tag.SNBT()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
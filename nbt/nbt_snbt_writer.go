@@ -0,0 +1,255 @@
+package nbt
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SNBTFormatter converts tags to SNBT. Compound keys are always written in sorted order.
+type SNBTFormatter struct {
+	// Indent is repeated once per nesting level in pretty mode. An empty Indent produces compact, single line output.
+	Indent string
+	// NonFinite writes NaN and infinite floats as NaN, Infinity and -Infinity with their suffix instead of failing.
+	// SNBT has no syntax for these values, the game and ParseSNBT read them back as strings.
+	NonFinite bool
+}
+
+// SNBT returns the compact SNBT representation of t. The name of t itself is not part of the output.
+func (t *Tag) SNBT() (string, error) {
+	return (&SNBTFormatter{}).Format(t)
+}
+
+// Format returns the SNBT representation of t. The name of t itself is not part of the output. NaN and infinite
+// floats are an error, unless NonFinite is set.
+func (f *SNBTFormatter) Format(t *Tag) (s string, err error) {
+	if !f.NonFinite {
+		if err = checkFinite(t); err != nil {
+			return
+		}
+	}
+
+	sb := &strings.Builder{}
+
+	f.writeTag(sb, t, 0)
+
+	return sb.String(), nil
+}
+
+// checkFinite returns an error for the first NaN or infinite float in t.
+func checkFinite(t *Tag) error {
+	var v float64
+
+	switch tv := t.Value.(type) {
+	case float32:
+		v = float64(tv)
+	case float64:
+		v = tv
+	case List:
+		for _, item := range tv {
+			if err := checkFinite(item); err != nil {
+				return err
+			}
+		}
+	case Compound:
+		for _, entry := range tv {
+			if err := checkFinite(entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Errorf("nbt: TAG_%s %q is %v, which has no SNBT syntax", typeName(t.Type), t.Name, v)
+	}
+
+	return nil
+}
+
+func (f *SNBTFormatter) pretty() bool {
+	return f.Indent != ""
+}
+
+// separator returns what goes between two elements written on the same line.
+func (f *SNBTFormatter) separator() string {
+	if f.pretty() {
+		return ", "
+	}
+
+	return ","
+}
+
+func (f *SNBTFormatter) newline(sb *strings.Builder, depth int) {
+	sb.WriteByte('\n')
+
+	for range depth {
+		sb.WriteString(f.Indent)
+	}
+}
+
+func (f *SNBTFormatter) writeTag(sb *strings.Builder, t *Tag, depth int) {
+	switch v := t.Value.(type) {
+	case int8:
+		sb.WriteString(strconv.FormatInt(int64(v), 10) + "b")
+	case int16:
+		sb.WriteString(strconv.FormatInt(int64(v), 10) + "s")
+	case int32:
+		sb.WriteString(strconv.FormatInt(int64(v), 10))
+	case int64:
+		sb.WriteString(strconv.FormatInt(v, 10) + "L")
+	case float32:
+		sb.WriteString(formatSNBTFloat(float64(v), 32) + "f")
+	case float64:
+		sb.WriteString(formatSNBTFloat(v, 64) + "d")
+	case string:
+		sb.WriteString(quoteSNBT(v))
+	case []byte:
+		writeSNBTArray(f, sb, "B", v, func(b byte) string {
+			return strconv.FormatInt(int64(int8(b)), 10) + "b"
+		})
+	case []int32:
+		writeSNBTArray(f, sb, "I", v, func(i int32) string {
+			return strconv.FormatInt(int64(i), 10)
+		})
+	case []int64:
+		writeSNBTArray(f, sb, "L", v, func(l int64) string {
+			return strconv.FormatInt(l, 10) + "L"
+		})
+	case List:
+		f.writeList(sb, v, depth)
+	case Compound:
+		f.writeCompound(sb, v, depth)
+	}
+}
+
+// formatSNBTFloat formats v without its suffix. NaN and infinite values are written as NaN, Infinity and -Infinity.
+func formatSNBTFloat(v float64, bitSize int) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "Infinity"
+	case math.IsInf(v, -1):
+		return "-Infinity"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, bitSize)
+	}
+}
+
+func writeSNBTArray[T any](f *SNBTFormatter, sb *strings.Builder, prefix string, values []T, format func(T) string) {
+	sb.WriteString("[" + prefix + ";")
+
+	for i, v := range values {
+		if i > 0 {
+			sb.WriteString(f.separator())
+		} else if f.pretty() {
+			sb.WriteByte(' ')
+		}
+
+		sb.WriteString(format(v))
+	}
+
+	sb.WriteByte(']')
+}
+
+func (f *SNBTFormatter) writeList(sb *strings.Builder, l List, depth int) {
+	sb.WriteByte('[')
+
+	// only lists of lists and compounds are spread over multiple lines
+	multiline := f.pretty() && len(l) > 0 && (l[0].Type == TypeList || l[0].Type == TypeCompound)
+
+	for i, item := range l {
+		if i > 0 {
+			if multiline {
+				sb.WriteByte(',')
+			} else {
+				sb.WriteString(f.separator())
+			}
+		}
+
+		if multiline {
+			f.newline(sb, depth+1)
+		}
+
+		f.writeTag(sb, item, depth+1)
+	}
+
+	if multiline {
+		f.newline(sb, depth)
+	}
+
+	sb.WriteByte(']')
+}
+
+func (f *SNBTFormatter) writeCompound(sb *strings.Builder, c Compound, depth int) {
+	sb.WriteByte('{')
+
+	for i, name := range slices.Sorted(maps.Keys(c)) {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+
+		if f.pretty() {
+			f.newline(sb, depth+1)
+		}
+
+		sb.WriteString(quoteSNBTKey(name))
+		sb.WriteByte(':')
+
+		if f.pretty() {
+			sb.WriteByte(' ')
+		}
+
+		f.writeTag(sb, c[name], depth+1)
+	}
+
+	if f.pretty() && len(c) > 0 {
+		f.newline(sb, depth)
+	}
+
+	sb.WriteByte('}')
+}
+
+// quoteSNBTKey leaves keys consisting only of characters allowed in unquoted strings as they are.
+func quoteSNBTKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+
+	for i := 0; i < len(key); i++ {
+		if !isUnquotedChar(key[i]) {
+			return quoteSNBT(key)
+		}
+	}
+
+	return key
+}
+
+// quoteSNBT quotes s with double quotes, unless s contains double but no single quotes.
+func quoteSNBT(s string) string {
+	quote := byte('"')
+
+	if strings.IndexByte(s, '"') != -1 && strings.IndexByte(s, '\'') == -1 {
+		quote = '\''
+	}
+
+	sb := strings.Builder{}
+
+	sb.Grow(len(s) + 2)
+	sb.WriteByte(quote)
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == quote || s[i] == '\\' {
+			sb.WriteByte('\\')
+		}
+
+		sb.WriteByte(s[i])
+	}
+
+	sb.WriteByte(quote)
+
+	return sb.String()
+}
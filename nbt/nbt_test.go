@@ -1,8 +1,13 @@
 package nbt
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
 	"math"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -198,7 +203,7 @@ func TestDecodeNanValue(t *testing.T) {
 		_ = f.Close()
 	}(f)
 
-	tag, err := newDecoder(f).decode()
+	tag, err := NewDecoder(f).decode()
 
 	if err != nil {
 		t.Fatal(err)
@@ -226,3 +231,569 @@ func TestDecodeNanValue(t *testing.T) {
 		t.Fatalf("expected NaN, got %f", listItem1Value)
 	}
 }
+
+func TestDecoderMultipleTags(t *testing.T) {
+	helloWorld, err := os.ReadFile("../testdata/hello_world.nbt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bigTestBytes, err := os.ReadFile("../testdata/bigtest.nbt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := append(append(append([]byte{}, helloWorld...), bigTestBytes...), helloWorld...)
+
+	d := NewDecoder(bytes.NewReader(stream))
+
+	ht := helloWorldTest{}
+
+	if err := d.Decode(&ht); err != nil {
+		t.Fatal(err)
+	}
+
+	if ht.HelloWorld.Name != "Bananrama" {
+		t.Fatalf("expected \"Bananrama\", got \"%s\"", ht.HelloWorld.Name)
+	}
+
+	tag := Tag{}
+
+	if err := d.Decode(&tag); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(tag.Name) != "Level" {
+		t.Fatalf("expected \"Level\", got \"%s\"", tag.Name)
+	}
+
+	if err := d.Decode(&tag); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(tag.Name) != "hello world" {
+		t.Fatalf("expected \"hello world\", got \"%s\"", tag.Name)
+	}
+
+	if err := d.Decode(&tag); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestDecoderTruncated(t *testing.T) {
+	helloWorld, err := os.ReadFile("../testdata/hello_world.nbt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag := Tag{}
+
+	if err := NewDecoder(bytes.NewReader(helloWorld[:len(helloWorld)-3])).Decode(&tag); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestDecoderHugeLength(t *testing.T) {
+	inputs := map[string]struct {
+		bs      []byte
+		dialect Dialect
+	}{
+		"int array": {[]byte{TypeIntArray, 0, 0, 0x7f, 0xff, 0xff, 0xff, 0, 0, 0, 1, 0}, DialectJava},
+		"list":      {[]byte{TypeList, 0, 0, TypeLong, 0x7f, 0xff, 0xff, 0xff, 0, 0, 0, 0}, DialectJava},
+		"string":    {[]byte{TypeString, 0, 0xff, 0xff, 0xff, 0xff, 0x07, 'a', 'b', 'c', 'd', 'e'}, DialectBedrockNetwork},
+		"varint":    {[]byte{TypeByteArray, 0, 0xfe, 0xff, 0xff, 0xff, 0x0f, 1, 2, 3, 4, 5}, DialectBedrockNetwork},
+	}
+
+	for name, input := range inputs {
+		d := NewDecoder(bytes.NewReader(input.bs))
+
+		d.SetDialect(input.dialect)
+
+		if err := d.Decode(&Tag{}); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("%s: expected io.ErrUnexpectedEOF, got %v", name, err)
+		}
+	}
+}
+
+func TestLittleEndianBedrockHeader(t *testing.T) {
+	f, err := os.Open("../testdata/bigtest.nbt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	bigTestTag := Tag{}
+
+	if err := NewDecoder(f).Decode(&bigTestTag); err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := Marshal(&bigTestTag, WithByteOrder(binary.LittleEndian), WithBedrockHeader(10))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if binary.LittleEndian.Uint32(bs[0:4]) != 10 {
+		t.Fatalf("expected version 10, got %d", binary.LittleEndian.Uint32(bs[0:4]))
+	}
+
+	if int(binary.LittleEndian.Uint32(bs[4:8])) != len(bs)-8 {
+		t.Fatalf("expected length %d, got %d", len(bs)-8, binary.LittleEndian.Uint32(bs[4:8]))
+	}
+
+	// name length of the root tag is little endian
+	if bs[9] != 5 || bs[10] != 0 {
+		t.Fatalf("expected little endian name length, got %v", bs[9:11])
+	}
+
+	d := NewDecoder(bytes.NewReader(bs))
+
+	d.SetByteOrder(binary.LittleEndian)
+	d.SetBedrockHeader(true)
+
+	bt := bigTest{}
+
+	if err := d.Decode(&bt); err != nil {
+		t.Fatal(err)
+	}
+
+	if d.BedrockHeader().Version != 10 {
+		t.Fatalf("expected version 10, got %d", d.BedrockHeader().Version)
+	}
+
+	if bt.Level.LongTest != 9223372036854775807 {
+		t.Fatalf("expected 9223372036854775807, got %d", bt.Level.LongTest)
+	}
+
+	if bt.Level.DoubleTest != 0.4931287132182315 {
+		t.Fatalf("expected 0.4931287132182315, got %f", bt.Level.DoubleTest)
+	}
+
+	if len(bt.Level.ByteArrayTest) != 1000 {
+		t.Fatalf("expected len=1000, got len=%d", len(bt.Level.ByteArrayTest))
+	}
+
+	if len(bt.Level.ListTestLong) != 5 || bt.Level.ListTestLong[4] != 15 {
+		t.Fatalf("expected [11 12 13 14 15], got %v", bt.Level.ListTestLong)
+	}
+
+	if bt.Level.StringTest != "HELLO WORLD THIS IS A TEST STRING ÅÄÖ!" {
+		t.Fatalf("expected \"HELLO WORLD THIS IS A TEST STRING ÅÄÖ!\", got \"%s\"", bt.Level.StringTest)
+	}
+}
+
+// allTypesTag returns a compound containing every tag type.
+func allTypesTag() *Tag {
+	return &Tag{
+		Type: TypeCompound,
+		Name: []byte("all types"),
+		Value: Compound{
+			"byte":      {Type: TypeByte, Name: []byte("byte"), Value: int8(-12)},
+			"short":     {Type: TypeShort, Name: []byte("short"), Value: int16(-1234)},
+			"int":       {Type: TypeInt, Name: []byte("int"), Value: int32(-123456789)},
+			"long":      {Type: TypeLong, Name: []byte("long"), Value: int64(-1234567890123456789)},
+			"float":     {Type: TypeFloat, Name: []byte("float"), Value: float32(0.25)},
+			"double":    {Type: TypeDouble, Name: []byte("double"), Value: 1.0 / 3},
+			"byteArray": {Type: TypeByteArray, Name: []byte("byteArray"), Value: []byte{0, 1, 255}},
+			"string":    {Type: TypeString, Name: []byte("string"), Value: "Hello ÅÄÖ"},
+			"list": {Type: TypeList, Name: []byte("list"), Value: List{
+				{Type: TypeInt, Value: int32(1)},
+				{Type: TypeInt, Value: int32(-2)},
+			}},
+			"compound": {Type: TypeCompound, Name: []byte("compound"), Value: Compound{
+				"nested": {Type: TypeString, Name: []byte("nested"), Value: "value"},
+			}},
+			"intArray":  {Type: TypeIntArray, Name: []byte("intArray"), Value: []int32{math.MinInt32, 0, math.MaxInt32}},
+			"longArray": {Type: TypeLongArray, Name: []byte("longArray"), Value: []int64{math.MinInt64, 0, math.MaxInt64}},
+		},
+	}
+}
+
+func TestBedrockNetworkDialect(t *testing.T) {
+	bs, err := Marshal(&Tag{Type: TypeInt, Name: []byte("a"), Value: int32(-1)}, WithDialect(DialectBedrockNetwork))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{TypeInt, 1, 'a', 1}
+
+	if !bytes.Equal(bs, expected) {
+		t.Fatalf("expected %v, got %v", expected, bs)
+	}
+
+	for _, dialect := range []Dialect{DialectJava, DialectBedrock, DialectBedrockNetwork} {
+		buf := new(bytes.Buffer)
+
+		e := NewEncoder(buf)
+
+		e.SetDialect(dialect)
+
+		if err := e.Encode(allTypesTag()); err != nil {
+			t.Fatal(err)
+		}
+
+		d := NewDecoder(buf)
+
+		d.SetDialect(dialect)
+
+		tag := Tag{}
+
+		if err := d.Decode(&tag); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(&tag, allTypesTag()) {
+			t.Fatalf("dialect %d: expected %v, got %v", dialect, allTypesTag(), &tag)
+		}
+	}
+}
+
+func TestNamelessRoot(t *testing.T) {
+	type textComponent struct {
+		Root struct {
+			Text  string `nbt:"text"`
+			Color string `nbt:"color"`
+		} `nbt:""`
+	}
+
+	payload := []byte{
+		TypeCompound,
+		TypeString, 0, 4, 't', 'e', 'x', 't', 0, 2, 'h', 'i',
+		TypeEnd,
+	}
+
+	tc := textComponent{}
+
+	if err := Unmarshal(payload, &tc, WithNamelessRoot()); err != nil {
+		t.Fatal(err)
+	}
+
+	if tc.Root.Text != "hi" {
+		t.Fatalf("expected \"hi\", got \"%s\"", tc.Root.Text)
+	}
+
+	tc.Root.Color = "red"
+
+	bs, err := Marshal(&tc, WithNamelessRoot())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bs[0] != TypeCompound || bs[1] != TypeString {
+		t.Fatalf("expected nameless compound, got %v", bs)
+	}
+
+	d := NewDecoder(bytes.NewReader(bs))
+
+	d.SetNamelessRoot(true)
+
+	tag := Tag{}
+
+	if err := d.Decode(&tag); err != nil {
+		t.Fatal(err)
+	}
+
+	if colorTag, ok := tag.Find("color"); !ok || colorTag.Value != "red" {
+		t.Fatalf("expected color \"red\", got %v", colorTag)
+	}
+}
+
+func TestFieldOptions(t *testing.T) {
+	type position struct {
+		X int32 `nbt:"x"`
+		Y int32 `nbt:"y"`
+	}
+
+	type entity struct {
+		position
+		ID       string    `nbt:"id"`
+		Name     string    `nbt:"CustomName,omitempty"`
+		OnGround bool      `nbt:"OnGround"`
+		UUID     IntArray  `nbt:"UUID"`
+		Rest     Compound  `nbt:",rest"`
+		Tags     []string  `nbt:"Tags,omitempty"`
+		Extra    *Tag      `nbt:"extra"`
+		Inner    struct{}  `nbt:"inner"`
+		Counts   []int32   `nbt:"counts"`
+		Flags    []bool    `nbt:"flags"`
+		Kind     uint8     `nbt:"kind"`
+		Level    int8      `nbt:"level"`
+		Seen     LongArray `nbt:"seen"`
+		Unused   position  `nbt:"unused,omitempty"`
+	}
+
+	tag := &Tag{Type: TypeCompound, Name: []byte{}, Value: Compound{
+		"x":        {Type: TypeInt, Name: []byte("x"), Value: int32(1)},
+		"y":        {Type: TypeInt, Name: []byte("y"), Value: int32(2)},
+		"id":       {Type: TypeString, Name: []byte("id"), Value: "minecraft:pig"},
+		"OnGround": {Type: TypeByte, Name: []byte("OnGround"), Value: int8(1)},
+		"UUID":     {Type: TypeIntArray, Name: []byte("UUID"), Value: []int32{1, 2, 3, 4}},
+		"extra":    {Type: TypeShort, Name: []byte("extra"), Value: int16(5)},
+		"Saddle":   {Type: TypeByte, Name: []byte("Saddle"), Value: int8(1)},
+		"Motion":   {Type: TypeList, Name: []byte("Motion"), Value: List{}},
+	}}
+
+	e := entity{}
+
+	if err := UnmarshalTag(&e, tag); err != nil {
+		t.Fatal(err)
+	}
+
+	if e.X != 1 || e.Y != 2 || !e.OnGround || len(e.UUID) != 4 || e.Extra.Value != int16(5) {
+		t.Fatalf("unexpected entity %+v", e)
+	}
+
+	if len(e.Rest) != 2 || e.Rest["Saddle"] == nil || e.Rest["Motion"] == nil {
+		t.Fatalf("expected Saddle and Motion in rest, got %v", e.Rest)
+	}
+
+	e.Flags = []bool{true, false}
+	e.Kind = 200
+
+	res, err := MarshalTag(&e)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTypes := map[string]int{
+		"x": TypeInt, "y": TypeInt, "id": TypeString, "OnGround": TypeByte, "UUID": TypeIntArray,
+		"extra": TypeShort, "inner": TypeCompound, "counts": TypeList, "flags": TypeList, "kind": TypeByte,
+		"level": TypeByte, "seen": TypeLongArray, "Saddle": TypeByte, "Motion": TypeList,
+	}
+
+	c := res.Value.(Compound)
+
+	if len(c) != len(expectedTypes) {
+		t.Fatalf("expected %d entries, got %d", len(expectedTypes), len(c))
+	}
+
+	for name, typ := range expectedTypes {
+		if c[name] == nil || c[name].Type != typ {
+			t.Fatalf("expected %s to have type %d, got %v", name, typ, c[name])
+		}
+	}
+
+	if c["kind"].Value != int8(-56) || c["flags"].Value.(List)[0].Value != int8(1) {
+		t.Fatalf("unexpected kind %v or flags %v", c["kind"].Value, c["flags"].Value)
+	}
+
+	if _, err := Marshal(&e); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnmarshalWidening(t *testing.T) {
+	tag, err := ParseSNBT(`{short:3s,byte:-1b,float:1.5f,flag:1b,ints:[I;1,2],bytes:[B;-1b,2b],kind:200}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := struct {
+		Short int32     `nbt:"short"`
+		Byte  int64     `nbt:"byte"`
+		Float float64   `nbt:"float"`
+		Flag  bool      `nbt:"flag"`
+		Ints  []int64   `nbt:"ints"`
+		Bytes []int16   `nbt:"bytes"`
+		Kind  int       `nbt:"kind"`
+		Array IntArray  `nbt:"ints"`
+		Raw   []byte    `nbt:"bytes"`
+		Any   *Tag      `nbt:"short"`
+		Copy  Tag       `nbt:"float"`
+		List  []float64 `nbt:"missing"`
+	}{}
+
+	if err = UnmarshalTag(&v, tag); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Short != 3 || v.Byte != -1 || v.Float != 1.5 || !v.Flag || v.Kind != 200 {
+		t.Fatalf("unexpected numbers %+v", v)
+	}
+
+	if !reflect.DeepEqual(v.Ints, []int64{1, 2}) || !reflect.DeepEqual(v.Bytes, []int16{-1, 2}) {
+		t.Fatalf("unexpected arrays %v, %v", v.Ints, v.Bytes)
+	}
+
+	if len(v.Array) != 2 || len(v.Raw) != 2 || v.Any.Value != int16(3) || v.Copy.Value != float32(1.5) {
+		t.Fatalf("unexpected values %+v", v)
+	}
+}
+
+func TestUnmarshalTypeError(t *testing.T) {
+	tag, err := ParseSNBT(`{Data:{Inventory:[{Count:1b},{Count:64L}],Name:"x",Pos:[1d,2d]}}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type item struct {
+		Count int32 `nbt:"Count"`
+	}
+
+	tests := []struct {
+		v       any
+		path    string
+		tagType int
+	}{
+		{&struct {
+			Data struct {
+				Inventory []item `nbt:"Inventory"`
+			} `nbt:"Data"`
+		}{}, "Data.Inventory[1].Count", TypeLong},
+		{&struct {
+			Data struct {
+				Name int32 `nbt:"Name"`
+			} `nbt:"Data"`
+		}{}, "Data.Name", TypeString},
+		{&struct {
+			Data struct {
+				Pos []float32 `nbt:"Pos"`
+			} `nbt:"Data"`
+		}{}, "Data.Pos[0]", TypeDouble},
+		{&struct {
+			Data []string `nbt:"Data"`
+		}{}, "Data", TypeCompound},
+		{&struct {
+			Data struct {
+				Name bool `nbt:"Name"`
+			} `nbt:"Data"`
+		}{}, "Data.Name", TypeString},
+	}
+
+	for _, test := range tests {
+		err := UnmarshalTag(test.v, tag)
+
+		var typeErr *UnmarshalTypeError
+
+		if !errors.As(err, &typeErr) {
+			t.Fatalf("expected *UnmarshalTypeError, got %v", err)
+		}
+
+		if typeErr.Path != test.path || typeErr.TagType != test.tagType {
+			t.Fatalf("expected error at %s for type %d, got %v", test.path, test.tagType, typeErr)
+		}
+	}
+
+	if err := UnmarshalTag(struct{}{}, tag); err == nil {
+		t.Fatalf("expected error for non-pointer value")
+	}
+}
+
+func TestMapsPointersAny(t *testing.T) {
+	type position struct {
+		X int32 `nbt:"x"`
+		Z int32 `nbt:"z"`
+	}
+
+	input := `{scores:{alice:3,bob:5},lang:{en:{hi:"hello"}},opt:7,spawn:{x:1,z:2},grid:[[1,2],[3]],` +
+		`arrays:[[I;1,2],[I;3]],pos:[1.5d,64d,-2.5d],payload:{a:[1b,2b],b:[L;4L],c:{d:"e"}}}`
+
+	tag, err := ParseSNBT(input)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := struct {
+		Scores  map[string]int32             `nbt:"scores"`
+		Lang    map[string]map[string]string `nbt:"lang"`
+		Opt     *int32                       `nbt:"opt"`
+		Missing *string                      `nbt:"missing"`
+		Spawn   *position                    `nbt:"spawn"`
+		Grid    [][]int32                    `nbt:"grid"`
+		Arrays  []IntArray                   `nbt:"arrays"`
+		Pos     [3]float64                   `nbt:"pos"`
+		Payload any                          `nbt:"payload"`
+	}{}
+
+	if err = UnmarshalTag(&v, tag); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Scores["bob"] != 5 || v.Lang["en"]["hi"] != "hello" || *v.Opt != 7 || v.Missing != nil || v.Spawn.Z != 2 {
+		t.Fatalf("unexpected values %+v", v)
+	}
+
+	if !reflect.DeepEqual(v.Grid, [][]int32{{1, 2}, {3}}) || len(v.Arrays) != 2 || v.Pos != [3]float64{1.5, 64, -2.5} {
+		t.Fatalf("unexpected lists %v, %v, %v", v.Grid, v.Arrays, v.Pos)
+	}
+
+	payload, ok := v.Payload.(map[string]any)
+
+	if !ok || !reflect.DeepEqual(payload["a"], []any{int8(1), int8(2)}) || payload["c"].(map[string]any)["d"] != "e" {
+		t.Fatalf("unexpected payload %#v", v.Payload)
+	}
+
+	res, err := MarshalTag(&v)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, s := snbt(t, tag), snbt(t, res); s != expected {
+		t.Fatalf("expected %s, got %s", expected, s)
+	}
+
+	short := struct {
+		Pos [2]float64 `nbt:"pos"`
+	}{}
+
+	if err = UnmarshalTag(&short, tag); err == nil {
+		t.Fatalf("expected error for array length mismatch")
+	}
+
+	wrongKey := struct {
+		Scores map[int]int32 `nbt:"scores"`
+	}{}
+
+	var typeErr *UnmarshalTypeError
+
+	if err = UnmarshalTag(&wrongKey, tag); !errors.As(err, &typeErr) {
+		t.Fatalf("expected *UnmarshalTypeError for int keys, got %v", err)
+	}
+}
+
+func TestEndListWithItems(t *testing.T) {
+	// a compound holding the list "l" of two TAG_End items
+	bs := []byte{0x0a, 0, 0, 0x09, 0, 1, 'l', 0x00, 0, 0, 0, 2, 0x00}
+
+	var v any
+
+	if err := Unmarshal(bs, &v); err == nil {
+		t.Fatalf("expected error for TAG_End list with items, got %v", v)
+	}
+
+	// hand built lists may still contain nil items
+	tag := &Tag{Type: TypeCompound, Value: Compound{
+		"l": {Type: TypeList, Name: []byte("l"), Value: List{nil, nil}},
+	}}
+
+	dsts := []any{
+		new(any),
+		&struct {
+			L []int32 `nbt:"l"`
+		}{},
+		&struct {
+			L [2]int32 `nbt:"l"`
+		}{},
+	}
+
+	for _, dst := range dsts {
+		if err := UnmarshalTag(dst, tag); err == nil {
+			t.Fatalf("expected error for nil list items in %T", dst)
+		}
+	}
+}
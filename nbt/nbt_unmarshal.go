@@ -0,0 +1,363 @@
+package nbt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var (
+	tagType    = reflect.TypeOf(Tag{})
+	tagPtrType = reflect.TypeOf(&Tag{})
+)
+
+// UnmarshalTypeError describes a tag that can't be stored in a Go value of a specific type.
+type UnmarshalTypeError struct {
+	// Path is the path of the tag below the tag passed to UnmarshalTag, e.g. "Data.Player.Inventory[0].id".
+	Path string
+	// Type is the Go type the tag was to be stored in.
+	Type reflect.Type
+	// TagType is the type of the tag.
+	TagType int
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("nbt: %s: cannot unmarshal TAG_%s into Go value of type %s", pathOrRoot(e.Path), typeName(e.TagType), e.Type)
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// unmarshalValue stores tag in val, which has to be addressable. Unmarshaler implementations are called after
+// the tag was stored.
+func unmarshalValue(val reflect.Value, tag *Tag, path string) (err error) {
+	// lists and compounds built by hand may contain nil tags
+	if tag == nil {
+		return fmt.Errorf("nbt: %s: nil tag", pathOrRoot(path))
+	}
+
+	switch val.Type() {
+	case tagType:
+		val.Set(reflect.ValueOf(*tag))
+
+		return
+	case tagPtrType:
+		val.Set(reflect.ValueOf(tag))
+
+		return
+	}
+
+	if err = decodeValue(val, tag, path); err != nil {
+		return
+	}
+
+	if u, ok := val.Addr().Interface().(Unmarshaler); ok {
+		return u.UnmarshalTag(tag)
+	}
+
+	return
+}
+
+func decodeValue(val reflect.Value, tag *Tag, path string) (err error) {
+	typeError := &UnmarshalTypeError{Path: path, Type: val.Type(), TagType: tag.Type}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		c, ok := tag.Value.(Compound)
+
+		if !ok {
+			return typeError
+		}
+
+		return decodeStruct(val, c, path)
+	case reflect.Bool:
+		b, ok := tag.Value.(int8)
+
+		if !ok {
+			return typeError
+		}
+
+		val.SetBool(b != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, bits, ok := tagInt(tag)
+
+		// only widening conversions, a TAG_Int doesn't fit into an int16 even if its value would
+		if !ok || bits > val.Type().Bits() {
+			return typeError
+		}
+
+		val.SetInt(n)
+	case reflect.Uint8:
+		// counterpart of Marshal writing uint8 values as bytes
+		b, ok := tag.Value.(int8)
+
+		if !ok {
+			return typeError
+		}
+
+		val.SetUint(uint64(uint8(b)))
+	case reflect.Float32, reflect.Float64:
+		var f float64
+
+		switch v := tag.Value.(type) {
+		case float32:
+			f = float64(v)
+		case float64:
+			if val.Kind() == reflect.Float32 {
+				return typeError
+			}
+
+			f = v
+		default:
+			return typeError
+		}
+
+		val.SetFloat(f)
+	case reflect.String:
+		s, ok := tag.Value.(string)
+
+		if !ok {
+			return typeError
+		}
+
+		val.SetString(s)
+	case reflect.Slice:
+		return decodeSlice(val, tag, path, typeError)
+	case reflect.Array:
+		return decodeArray(val, tag, path, typeError)
+	case reflect.Map:
+		c, ok := tag.Value.(Compound)
+
+		if !ok || val.Type().Key().Kind() != reflect.String {
+			return typeError
+		}
+
+		return decodeMap(val, c, path)
+	case reflect.Ptr:
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+
+		return unmarshalValue(val.Elem(), tag, path)
+	case reflect.Interface:
+		if val.NumMethod() > 0 {
+			return typeError
+		}
+
+		var v any
+
+		if v, err = anyValue(tag, path); err != nil {
+			return
+		}
+
+		if v == nil {
+			return typeError
+		}
+
+		val.Set(reflect.ValueOf(v))
+	default:
+		return typeError
+	}
+
+	return
+}
+
+// tagInt returns the value of an integer tag and the size of its type in bits.
+func tagInt(tag *Tag) (n int64, bits int, ok bool) {
+	switch v := tag.Value.(type) {
+	case int8:
+		return int64(v), 8, true
+	case int16:
+		return int64(v), 16, true
+	case int32:
+		return int64(v), 32, true
+	case int64:
+		return v, 64, true
+	default:
+		return
+	}
+}
+
+func decodeStruct(val reflect.Value, c Compound, path string) (err error) {
+	fields := fieldsOf(val.Type())
+
+	for _, f := range fields.fields {
+		if child, ok := c[f.name]; ok {
+			if err = unmarshalValue(val.FieldByIndex(f.index), child, joinPath(path, f.name)); err != nil {
+				return
+			}
+		}
+	}
+
+	if fields.rest == nil {
+		return
+	}
+
+	rest := Compound{}
+
+	for name, child := range c {
+		if !fields.has(name) {
+			rest[name] = child
+		}
+	}
+
+	if len(rest) > 0 {
+		val.FieldByIndex(fields.rest).Set(reflect.ValueOf(rest))
+	}
+
+	return
+}
+
+func decodeSlice(val reflect.Value, tag *Tag, path string, typeError error) (err error) {
+	tv := reflect.ValueOf(tag.Value)
+
+	// arrays into slices of their element type and lists into List or []*Tag are shared, not copied
+	if tv.Kind() == reflect.Slice && tv.Type().ConvertibleTo(val.Type()) {
+		val.Set(tv.Convert(val.Type()))
+
+		return
+	}
+
+	items, ok := listItems(tag)
+
+	if !ok {
+		return typeError
+	}
+
+	s := reflect.MakeSlice(val.Type(), len(items), len(items))
+
+	for i, item := range items {
+		if err = unmarshalValue(s.Index(i), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return
+		}
+	}
+
+	val.Set(s)
+
+	return
+}
+
+// decodeArray decodes a list or array tag into a Go array of the same length.
+func decodeArray(val reflect.Value, tag *Tag, path string, typeError error) (err error) {
+	items, ok := listItems(tag)
+
+	if !ok {
+		return typeError
+	}
+
+	if len(items) != val.Len() {
+		return fmt.Errorf("nbt: %s: cannot unmarshal %d elements into Go value of type %s", pathOrRoot(path), len(items), val.Type())
+	}
+
+	for i, item := range items {
+		if err = unmarshalValue(val.Index(i), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// listItems returns the items of a list tag, or the elements of an array tag as tags.
+func listItems(tag *Tag) (items List, ok bool) {
+	switch v := tag.Value.(type) {
+	case List:
+		items = v
+	case []byte:
+		items = arrayItems(v, TypeByte, func(b byte) any { return int8(b) })
+	case []int32:
+		items = arrayItems(v, TypeInt, func(i int32) any { return i })
+	case []int64:
+		items = arrayItems(v, TypeLong, func(l int64) any { return l })
+	default:
+		return nil, false
+	}
+
+	return items, true
+}
+
+// decodeMap decodes the entries of c into a map with string keys. Entries are added to an existing map.
+func decodeMap(val reflect.Value, c Compound, path string) (err error) {
+	typ := val.Type()
+
+	// Compound and types based on it share the tags
+	if reflect.TypeOf(c).ConvertibleTo(typ) {
+		val.Set(reflect.ValueOf(c).Convert(typ))
+
+		return
+	}
+
+	if val.IsNil() {
+		val.Set(reflect.MakeMapWithSize(typ, len(c)))
+	}
+
+	for name, child := range c {
+		elem := reflect.New(typ.Elem()).Elem()
+
+		if err = unmarshalValue(elem, child, joinPath(path, name)); err != nil {
+			return
+		}
+
+		val.SetMapIndex(reflect.ValueOf(name).Convert(typ.Key()), elem)
+	}
+
+	return
+}
+
+// anyValue returns the value stored in an empty interface: compounds become map[string]any, lists []any, int and
+// long arrays IntArray and LongArray, everything else keeps its value. Marshalling the result restores the tag.
+func anyValue(tag *Tag, path string) (res any, err error) {
+	switch v := tag.Value.(type) {
+	case Compound:
+		m := make(map[string]any, len(v))
+
+		for name, child := range v {
+			if child == nil {
+				return nil, fmt.Errorf("nbt: %s: nil compound entry", joinPath(path, name))
+			}
+
+			if m[name], err = anyValue(child, joinPath(path, name)); err != nil {
+				return
+			}
+		}
+
+		return m, nil
+	case List:
+		l := make([]any, len(v))
+
+		for i, item := range v {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+
+			if item == nil {
+				return nil, fmt.Errorf("nbt: %s: nil list item", itemPath)
+			}
+
+			if l[i], err = anyValue(item, itemPath); err != nil {
+				return
+			}
+		}
+
+		return l, nil
+	case []int32:
+		return IntArray(v), nil
+	case []int64:
+		return LongArray(v), nil
+	default:
+		return v, nil
+	}
+}
+
+// arrayItems returns the elements of an array tag as tags, to decode them into slices of other element types.
+func arrayItems[T any](values []T, typ int, value func(T) any) List {
+	items := make(List, len(values))
+
+	for i, v := range values {
+		items[i] = &Tag{Type: typ, Value: value(v)}
+	}
+
+	return items
+}
@@ -0,0 +1,262 @@
+package region
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// Minecraft writes LZ4 compressed chunks with lz4-java's LZ4BlockOutputStream: a sequence of blocks, each with a
+// 21 byte header, terminated by an empty block.
+
+var lz4BlockMagic = []byte("LZ4Block")
+
+const (
+	lz4BlockHeaderSize     = 21
+	lz4MethodRaw           = 0x10
+	lz4MethodLZ4           = 0x20
+	lz4ChecksumSeed        = 0x9747b28c
+	lz4ChecksumMask        = 0x0fffffff
+	lz4MaxDecompressedSize = 1 << 25
+)
+
+var errLZ4Corrupt = errors.New("region: corrupt lz4 data")
+
+// decompressLZ4Block decompresses a single raw LZ4 block into a buffer of size bytes.
+func decompressLZ4Block(src []byte, size int) (dst []byte, err error) {
+	dst = make([]byte, 0, size)
+
+	readLength := func(i int, length int) (int, int, error) {
+		if length != 15 {
+			return i, length, nil
+		}
+
+		for {
+			if i >= len(src) {
+				return i, 0, errLZ4Corrupt
+			}
+
+			b := src[i]
+			i++
+			length += int(b)
+
+			if b != 255 {
+				return i, length, nil
+			}
+		}
+	}
+
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+
+		var literals int
+
+		if i, literals, err = readLength(i, int(token>>4)); err != nil {
+			return
+		}
+
+		if i+literals > len(src) || len(dst)+literals > size {
+			return nil, errLZ4Corrupt
+		}
+
+		dst = append(dst, src[i:i+literals]...)
+		i += literals
+
+		// the last sequence only has literals
+		if i == len(src) {
+			break
+		}
+
+		if i+2 > len(src) {
+			return nil, errLZ4Corrupt
+		}
+
+		offset := int(binary.LittleEndian.Uint16(src[i:]))
+		i += 2
+
+		if offset == 0 || offset > len(dst) {
+			return nil, errLZ4Corrupt
+		}
+
+		var matchLength int
+
+		if i, matchLength, err = readLength(i, int(token&0x0f)); err != nil {
+			return
+		}
+
+		matchLength += 4
+
+		if len(dst)+matchLength > size {
+			return nil, errLZ4Corrupt
+		}
+
+		// matches may overlap with the bytes they produce, so copy byte by byte
+		start := len(dst) - offset
+
+		for j := range matchLength {
+			dst = append(dst, dst[start+j])
+		}
+	}
+
+	if len(dst) != size {
+		return nil, errLZ4Corrupt
+	}
+
+	return
+}
+
+// readLZ4BlockStream decompresses a complete LZ4BlockOutputStream.
+func readLZ4BlockStream(r io.Reader) (res []byte, err error) {
+	out := new(bytes.Buffer)
+	header := make([]byte, lz4BlockHeaderSize)
+
+	for {
+		if _, err = io.ReadFull(r, header); err != nil {
+			return
+		}
+
+		if !bytes.Equal(header[:8], lz4BlockMagic) {
+			return nil, fmt.Errorf("region: invalid lz4 block magic")
+		}
+
+		method := header[8] & 0xf0
+		compressedSize := int(int32(binary.LittleEndian.Uint32(header[9:])))
+		size := int(int32(binary.LittleEndian.Uint32(header[13:])))
+		checksum := binary.LittleEndian.Uint32(header[17:])
+
+		if size == 0 {
+			return out.Bytes(), nil
+		}
+
+		if compressedSize < 0 || size < 0 || size > lz4MaxDecompressedSize || compressedSize > lz4MaxDecompressedSize {
+			return nil, errLZ4Corrupt
+		}
+
+		compressed := make([]byte, compressedSize)
+
+		if _, err = io.ReadFull(r, compressed); err != nil {
+			return
+		}
+
+		var block []byte
+
+		switch method {
+		case lz4MethodRaw:
+			if compressedSize != size {
+				return nil, errLZ4Corrupt
+			}
+
+			block = compressed
+		case lz4MethodLZ4:
+			if block, err = decompressLZ4Block(compressed, size); err != nil {
+				return
+			}
+		default:
+			return nil, fmt.Errorf("region: unknown lz4 block method: %#x", method)
+		}
+
+		if xxHash32(block, lz4ChecksumSeed)&lz4ChecksumMask != checksum {
+			return nil, fmt.Errorf("region: lz4 block checksum mismatch")
+		}
+
+		out.Write(block)
+	}
+}
+
+const (
+	xxPrime1 uint32 = 2654435761
+	xxPrime2 uint32 = 2246822519
+	xxPrime3 uint32 = 3266489917
+	xxPrime4 uint32 = 668265263
+	xxPrime5 uint32 = 374761393
+)
+
+func xxRound(v, lane uint32) uint32 {
+	return bits.RotateLeft32(v+lane*xxPrime2, 13) * xxPrime1
+}
+
+// xxHash32 is the 32 bit xxHash of bs, used by lz4-java to checksum blocks.
+func xxHash32(bs []byte, seed uint32) (h uint32) {
+	n := len(bs)
+
+	if n >= 16 {
+		v1 := seed + xxPrime1 + xxPrime2
+		v2 := seed + xxPrime2
+		v3 := seed
+		v4 := seed - xxPrime1
+
+		for len(bs) >= 16 {
+			v1 = xxRound(v1, binary.LittleEndian.Uint32(bs[0:]))
+			v2 = xxRound(v2, binary.LittleEndian.Uint32(bs[4:]))
+			v3 = xxRound(v3, binary.LittleEndian.Uint32(bs[8:]))
+			v4 = xxRound(v4, binary.LittleEndian.Uint32(bs[12:]))
+			bs = bs[16:]
+		}
+
+		h = bits.RotateLeft32(v1, 1) + bits.RotateLeft32(v2, 7) + bits.RotateLeft32(v3, 12) + bits.RotateLeft32(v4, 18)
+	} else {
+		h = seed + xxPrime5
+	}
+
+	h += uint32(n)
+
+	for len(bs) >= 4 {
+		h += binary.LittleEndian.Uint32(bs) * xxPrime3
+		h = bits.RotateLeft32(h, 17) * xxPrime4
+		bs = bs[4:]
+	}
+
+	for _, b := range bs {
+		h += uint32(b) * xxPrime5
+		h = bits.RotateLeft32(h, 11) * xxPrime1
+	}
+
+	h ^= h >> 15
+	h *= xxPrime2
+	h ^= h >> 13
+	h *= xxPrime3
+	h ^= h >> 16
+
+	return
+}
+
+// lz4BlockSize is the default block size of LZ4BlockOutputStream.
+const lz4BlockSize = 1 << 16
+
+// lz4BlockLevel is the compression level LZ4BlockOutputStream stores in the low nibble of every block token.
+// LZ4BlockInputStream rejects blocks larger than 1 << (10 + level).
+var lz4BlockLevel = byte(bits.Len(lz4BlockSize-1) - 10)
+
+// writeLZ4BlockStream wraps bs in an LZ4BlockOutputStream. Blocks are stored with the raw method, which every
+// LZ4BlockInputStream reads, so no LZ4 compressor is needed.
+func writeLZ4BlockStream(bs []byte) []byte {
+	out := new(bytes.Buffer)
+	header := make([]byte, lz4BlockHeaderSize)
+
+	copy(header, lz4BlockMagic)
+
+	for len(bs) > 0 {
+		block := bs[:min(len(bs), lz4BlockSize)]
+		bs = bs[len(block):]
+
+		header[8] = lz4MethodRaw | lz4BlockLevel
+		binary.LittleEndian.PutUint32(header[9:], uint32(len(block)))
+		binary.LittleEndian.PutUint32(header[13:], uint32(len(block)))
+		binary.LittleEndian.PutUint32(header[17:], xxHash32(block, lz4ChecksumSeed)&lz4ChecksumMask)
+
+		out.Write(header)
+		out.Write(block)
+	}
+
+	// the end mark is an empty block
+	clear(header[8:])
+	header[8] = lz4MethodRaw | lz4BlockLevel
+
+	out.Write(header)
+
+	return out.Bytes()
+}
@@ -0,0 +1,316 @@
+package region
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+const (
+	SectorSize      = 4096
+	ChunksPerRegion = 32 * 32
+	headerSize      = 2 * SectorSize
+	externalFlag    = 0x80
+)
+
+// ErrChunkNotFound is returned when reading a chunk that is not present in the region file.
+var ErrChunkNotFound = errors.New("region: chunk not found")
+
+// Compression is the compression type stored in front of each chunk.
+type Compression byte
+
+const (
+	CompressionGzip Compression = 1
+	CompressionZlib Compression = 2
+	CompressionNone Compression = 3
+	CompressionLZ4  Compression = 4
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZlib:
+		return "zlib"
+	case CompressionNone:
+		return "none"
+	case CompressionLZ4:
+		return "lz4"
+	default:
+		return "unknown"
+	}
+}
+
+// ChunkPos is the position of a chunk inside its region, both coordinates are in [0, 32).
+type ChunkPos struct {
+	X int
+	Z int
+}
+
+// File is an Anvil (.mca) or McRegion (.mcr) region file holding up to 32×32 chunks. Methods taking chunk
+// coordinates accept both local and world chunk coordinates, only the lower 5 bits are used.
+type File struct {
+	f          *os.File
+	path       string
+	regionX    int
+	regionZ    int
+	hasPos     bool
+	locations  [ChunksPerRegion]uint32
+	timestamps [ChunksPerRegion]uint32
+}
+
+// FileName returns the name of the Anvil region file containing the region at regionX, regionZ.
+func FileName(regionX, regionZ int) string {
+	return fmt.Sprintf("r.%d.%d.mca", regionX, regionZ)
+}
+
+// RegionOf returns the position of the region containing the chunk at chunkX, chunkZ.
+func RegionOf(chunkX, chunkZ int) (regionX, regionZ int) {
+	return chunkX >> 5, chunkZ >> 5
+}
+
+// parseFileName extracts the region position from names like r.-1.2.mca.
+func parseFileName(name string) (regionX, regionZ int, ok bool) {
+	parts := strings.Split(filepath.Base(name), ".")
+
+	if len(parts) != 4 || parts[0] != "r" {
+		return
+	}
+
+	var err error
+
+	if regionX, err = strconv.Atoi(parts[1]); err != nil {
+		return
+	}
+
+	if regionZ, err = strconv.Atoi(parts[2]); err != nil {
+		return
+	}
+
+	ok = true
+
+	return
+}
+
+func chunkIndex(x, z int) int {
+	return (x & 31) + (z&31)*32
+}
+
+// Open opens the region file at path for reading.
+func Open(path string) (r *File, err error) {
+	return openFile(path, os.O_RDONLY)
+}
+
+func openFile(path string, flag int) (r *File, err error) {
+	f, err := os.OpenFile(path, flag, 0644)
+
+	if err != nil {
+		return
+	}
+
+	r = &File{
+		f:    f,
+		path: path,
+	}
+
+	r.regionX, r.regionZ, r.hasPos = parseFileName(path)
+
+	if err = r.readHeader(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return
+}
+
+// readHeader reads the location and timestamp tables. Empty files are treated as regions without chunks.
+func (r *File) readHeader() (err error) {
+	header := make([]byte, headerSize)
+
+	n, err := r.f.ReadAt(header, 0)
+
+	if n == 0 && errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("region: reading header: %w", err)
+	}
+
+	for i := range ChunksPerRegion {
+		r.locations[i] = binary.BigEndian.Uint32(header[i*4:])
+		r.timestamps[i] = binary.BigEndian.Uint32(header[SectorSize+i*4:])
+	}
+
+	return
+}
+
+func (r *File) Close() error {
+	return r.f.Close()
+}
+
+// Position returns the region position parsed from the file name. ok is false if the name doesn't follow the
+// r.<x>.<z>.mca pattern.
+func (r *File) Position() (regionX, regionZ int, ok bool) {
+	return r.regionX, r.regionZ, r.hasPos
+}
+
+// location returns the first sector and the sector count of a chunk.
+func (r *File) location(x, z int) (offset, count int) {
+	loc := r.locations[chunkIndex(x, z)]
+
+	return int(loc >> 8), int(loc & 0xff)
+}
+
+// HasChunk reports whether the chunk at x, z is present.
+func (r *File) HasChunk(x, z int) bool {
+	offset, count := r.location(x, z)
+
+	return offset != 0 || count != 0
+}
+
+// Chunks returns the positions of all present chunks.
+func (r *File) Chunks() (res []ChunkPos) {
+	for z := range 32 {
+		for x := range 32 {
+			if r.HasChunk(x, z) {
+				res = append(res, ChunkPos{X: x, Z: z})
+			}
+		}
+	}
+
+	return
+}
+
+// Timestamp returns the last modification time of the chunk at x, z, or the zero time if it's not set.
+func (r *File) Timestamp(x, z int) time.Time {
+	ts := r.timestamps[chunkIndex(x, z)]
+
+	if ts == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(ts), 0)
+}
+
+// externalPath returns the path of the .mcc file holding an oversized chunk.
+func (r *File) externalPath(x, z int) (string, error) {
+	if !r.hasPos {
+		return "", fmt.Errorf("region: %s: can't locate external chunk without region position", r.path)
+	}
+
+	return filepath.Join(filepath.Dir(r.path), fmt.Sprintf("c.%d.%d.mcc", r.regionX*32+(x&31), r.regionZ*32+(z&31))), nil
+}
+
+// ChunkData returns the still compressed data of the chunk at x, z and its compression. Chunks stored in
+// external .mcc files are read from there.
+func (r *File) ChunkData(x, z int) (data []byte, c Compression, err error) {
+	offset, count := r.location(x, z)
+
+	if offset == 0 && count == 0 {
+		return nil, 0, ErrChunkNotFound
+	}
+
+	header := make([]byte, 5)
+
+	if _, err = r.f.ReadAt(header, int64(offset)*SectorSize); err != nil {
+		return nil, 0, fmt.Errorf("region: reading chunk %d,%d: %w", x&31, z&31, err)
+	}
+
+	length := int(binary.BigEndian.Uint32(header))
+	c = Compression(header[4])
+
+	if c&externalFlag != 0 {
+		c &^= externalFlag
+
+		var path string
+
+		if path, err = r.externalPath(x, z); err != nil {
+			return
+		}
+
+		data, err = os.ReadFile(path)
+
+		return
+	}
+
+	if length < 1 || length+4 > count*SectorSize {
+		return nil, 0, fmt.Errorf("region: chunk %d,%d: invalid length %d for %d sectors", x&31, z&31, length, count)
+	}
+
+	data = make([]byte, length-1)
+
+	if _, err = r.f.ReadAt(data, int64(offset)*SectorSize+5); err != nil {
+		return nil, 0, fmt.Errorf("region: reading chunk %d,%d: %w", x&31, z&31, err)
+	}
+
+	return
+}
+
+// Decompress returns a reader yielding the uncompressed chunk NBT of data.
+func Decompress(data []byte, c Compression) (r io.Reader, err error) {
+	switch c {
+	case CompressionGzip:
+		return gzip.NewReader(bytes.NewReader(data))
+	case CompressionZlib:
+		return zlib.NewReader(bytes.NewReader(data))
+	case CompressionNone:
+		return bytes.NewReader(data), nil
+	case CompressionLZ4:
+		var res []byte
+
+		if res, err = readLZ4BlockStream(bytes.NewReader(data)); err != nil {
+			return
+		}
+
+		return bytes.NewReader(res), nil
+	default:
+		return nil, fmt.Errorf("region: unknown compression: %d", c)
+	}
+}
+
+// ReadChunk decodes the chunk at x, z into a tag tree.
+func (r *File) ReadChunk(x, z int) (tag *nbt.Tag, err error) {
+	data, c, err := r.ChunkData(x, z)
+
+	if err != nil {
+		return
+	}
+
+	cr, err := Decompress(data, c)
+
+	if err != nil {
+		return
+	}
+
+	tag = &nbt.Tag{}
+
+	if err = nbt.NewDecoder(cr).Decode(tag); err != nil {
+		return nil, fmt.Errorf("region: decoding chunk %d,%d: %w", x&31, z&31, err)
+	}
+
+	return
+}
+
+// UnmarshalChunk decodes the chunk at x, z into v using nbt.UnmarshalTag. The fields of v refer to the entries of
+// the chunk's root compound.
+func (r *File) UnmarshalChunk(x, z int, v any) (err error) {
+	tag, err := r.ReadChunk(x, z)
+
+	if err != nil {
+		return
+	}
+
+	return nbt.UnmarshalTag(v, tag)
+}
@@ -0,0 +1,251 @@
+package region
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+type testChunk struct {
+	Root struct {
+		XPos        int32  `nbt:"xPos"`
+		ZPos        int32  `nbt:"zPos"`
+		Status      string `nbt:"Status"`
+		DataVersion int32  `nbt:"DataVersion"`
+	} `nbt:""`
+}
+
+func chunkNBT(t *testing.T, x, z int32) []byte {
+	c := testChunk{}
+
+	c.Root.XPos = x
+	c.Root.ZPos = z
+	c.Root.Status = "minecraft:full"
+	c.Root.DataVersion = 3953
+
+	bs, err := nbt.Marshal(&c)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bs
+}
+
+func compress(t *testing.T, c Compression, bs []byte) []byte {
+	buf := new(bytes.Buffer)
+
+	switch c {
+	case CompressionGzip:
+		w := gzip.NewWriter(buf)
+		_, _ = w.Write(bs)
+		_ = w.Close()
+	case CompressionZlib:
+		w := zlib.NewWriter(buf)
+		_, _ = w.Write(bs)
+		_ = w.Close()
+	case CompressionLZ4:
+		// a single block consisting of one literal-only sequence
+		block := []byte{0xf0}
+		n := len(bs) - 15
+
+		for ; n >= 255; n -= 255 {
+			block = append(block, 255)
+		}
+
+		block = append(block, byte(n))
+		block = append(block, bs...)
+
+		header := make([]byte, lz4BlockHeaderSize)
+
+		copy(header, lz4BlockMagic)
+		header[8] = lz4MethodLZ4
+		binary.LittleEndian.PutUint32(header[9:], uint32(len(block)))
+		binary.LittleEndian.PutUint32(header[13:], uint32(len(bs)))
+		binary.LittleEndian.PutUint32(header[17:], xxHash32(bs, lz4ChecksumSeed)&lz4ChecksumMask)
+
+		buf.Write(header)
+		buf.Write(block)
+
+		end := make([]byte, lz4BlockHeaderSize)
+
+		copy(end, lz4BlockMagic)
+		end[8] = lz4MethodRaw
+		buf.Write(end)
+	default:
+		buf.Write(bs)
+	}
+
+	return buf.Bytes()
+}
+
+// writeTestRegion assembles a region file by hand, chunks are stored in consecutive sectors.
+func writeTestRegion(t *testing.T, path string, chunks map[ChunkPos][]byte, compressions map[ChunkPos]byte) {
+	header := make([]byte, headerSize)
+	body := new(bytes.Buffer)
+	sector := 2
+
+	for pos, data := range chunks {
+		payload := make([]byte, 5, 5+len(data))
+
+		binary.BigEndian.PutUint32(payload, uint32(len(data)+1))
+		payload[4] = compressions[pos]
+		payload = append(payload, data...)
+
+		for len(payload)%SectorSize != 0 {
+			payload = append(payload, 0)
+		}
+
+		count := len(payload) / SectorSize
+		i := chunkIndex(pos.X, pos.Z)
+
+		binary.BigEndian.PutUint32(header[i*4:], uint32(sector<<8|count))
+		binary.BigEndian.PutUint32(header[SectorSize+i*4:], 1700000000)
+
+		body.Write(payload)
+		sector += count
+	}
+
+	if err := os.WriteFile(path, append(header, body.Bytes()...), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadRegion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName(-1, 2))
+
+	chunks := map[ChunkPos][]byte{}
+	compressions := map[ChunkPos]byte{}
+
+	for pos, c := range map[ChunkPos]Compression{
+		{X: 0, Z: 0}:   CompressionZlib,
+		{X: 1, Z: 0}:   CompressionGzip,
+		{X: 31, Z: 31}: CompressionNone,
+		{X: 2, Z: 5}:   CompressionLZ4,
+	} {
+		chunks[pos] = compress(t, c, chunkNBT(t, int32(-32+pos.X), int32(64+pos.Z)))
+		compressions[pos] = byte(c)
+	}
+
+	// oversized chunks only keep the compression byte in the region file
+	external := ChunkPos{X: 3, Z: 3}
+
+	chunks[external] = nil
+	compressions[external] = byte(CompressionZlib) | externalFlag
+
+	mcc := compress(t, CompressionZlib, chunkNBT(t, -29, 67))
+
+	if err := os.WriteFile(filepath.Join(dir, "c.-29.67.mcc"), mcc, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestRegion(t, path, chunks, compressions)
+
+	r, err := Open(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(r *File) {
+		_ = r.Close()
+	}(r)
+
+	if len(r.Chunks()) != 5 {
+		t.Fatalf("expected 5 chunks, got %d", len(r.Chunks()))
+	}
+
+	for pos := range chunks {
+		if !r.HasChunk(pos.X, pos.Z) {
+			t.Fatalf("expected chunk %v", pos)
+		}
+
+		c := testChunk{}
+
+		if err := r.UnmarshalChunk(pos.X, pos.Z, &c.Root); err != nil {
+			t.Fatalf("chunk %v: %s", pos, err)
+		}
+
+		if c.Root.XPos != int32(-32+pos.X) || c.Root.ZPos != int32(64+pos.Z) {
+			t.Fatalf("chunk %v: unexpected position %d,%d", pos, c.Root.XPos, c.Root.ZPos)
+		}
+
+		if c.Root.Status != "minecraft:full" {
+			t.Fatalf("expected minecraft:full, got %s", c.Root.Status)
+		}
+
+		if !r.Timestamp(pos.X, pos.Z).Equal(time.Unix(1700000000, 0)) {
+			t.Fatalf("unexpected timestamp %s", r.Timestamp(pos.X, pos.Z))
+		}
+	}
+
+	// world chunk coordinates work as well
+	tag, err := r.ReadChunk(-30, 69)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if xPos, ok := tag.Find("xPos"); !ok || xPos.Value != int32(-30) {
+		t.Fatalf("expected xPos -30, got %v", xPos)
+	}
+
+	if _, err := r.ReadChunk(5, 5); !errors.Is(err, ErrChunkNotFound) {
+		t.Fatalf("expected ErrChunkNotFound, got %v", err)
+	}
+}
+
+func TestDecompressLZ4Block(t *testing.T) {
+	// literals "abc", then a 12 byte match at offset 3
+	src := []byte{0x38, 'a', 'b', 'c', 3, 0}
+
+	dst, err := decompressLZ4Block(src, 15)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(dst) != "abcabcabcabcabc" {
+		t.Fatalf("expected abcabcabcabcabc, got %s", dst)
+	}
+
+	if _, err := decompressLZ4Block([]byte{0x38, 'a', 'b', 'c', 4, 0}, 15); err == nil {
+		t.Fatal("expected error for offset out of range")
+	}
+}
+
+func TestXXHash32(t *testing.T) {
+	tests := map[string]uint32{
+		"":    0x02cc5d05,
+		"abc": 0x32d153ff,
+		"Nobody inspects the spammish repetition": 0xe2293b2f,
+	}
+
+	for s, expected := range tests {
+		if h := xxHash32([]byte(s), 0); h != expected {
+			t.Fatalf("%q: expected %#x, got %#x", s, expected, h)
+		}
+	}
+}
+
+func TestWriteLZ4BlockStream(t *testing.T) {
+	// the bytes lz4-java's LZ4BlockOutputStream writes with the default 64 KiB block size, it stores short input raw
+	expected := []byte{
+		'L', 'Z', '4', 'B', 'l', 'o', 'c', 'k', 0x16, 5, 0, 0, 0, 5, 0, 0, 0, 0xe3, 0xbf, 0x41, 0x0a,
+		'h', 'e', 'l', 'l', 'o',
+		'L', 'Z', '4', 'B', 'l', 'o', 'c', 'k', 0x16, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	}
+
+	if bs := writeLZ4BlockStream([]byte("hello")); !bytes.Equal(bs, expected) {
+		t.Fatalf("expected %v, got %v", expected, bs)
+	}
+}
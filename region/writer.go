@@ -0,0 +1,296 @@
+package region
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+// maxSectorCount is the largest sector count a location entry can hold. Bigger chunks go to external .mcc files.
+const maxSectorCount = 255
+
+// OpenWrite opens the region file at path for reading and writing, creating it if it doesn't exist.
+func OpenWrite(path string) (r *File, err error) {
+	if r, err = openFile(path, os.O_RDWR|os.O_CREATE); err != nil {
+		return
+	}
+
+	info, err := r.f.Stat()
+
+	if err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+
+	if info.Size() < headerSize {
+		if err = r.writeHeader(); err != nil {
+			_ = r.Close()
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// Compress compresses uncompressed chunk NBT with c.
+func Compress(bs []byte, c Compression) (res []byte, err error) {
+	buf := new(bytes.Buffer)
+
+	var w io.WriteCloser
+
+	switch c {
+	case CompressionGzip:
+		w = gzip.NewWriter(buf)
+	case CompressionZlib:
+		w = zlib.NewWriter(buf)
+	case CompressionNone:
+		return bs, nil
+	case CompressionLZ4:
+		return writeLZ4BlockStream(bs), nil
+	default:
+		return nil, fmt.Errorf("region: unknown compression: %d", c)
+	}
+
+	if _, err = w.Write(bs); err != nil {
+		return
+	}
+
+	if err = w.Close(); err != nil {
+		return
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteChunk marshals v, either a *nbt.Tag or a value supported by nbt.Marshal, and stores it compressed with c as
+// the chunk at x, z.
+func (r *File) WriteChunk(x, z int, v any, c Compression) (err error) {
+	bs, err := nbt.Marshal(v)
+
+	if err != nil {
+		return
+	}
+
+	data, err := Compress(bs, c)
+
+	if err != nil {
+		return
+	}
+
+	return r.WriteChunkData(x, z, data, c)
+}
+
+// WriteChunkData stores already compressed chunk data as the chunk at x, z and updates its timestamp. The chunk is
+// rewritten in place if it still fits its sectors, otherwise it's moved to the first free sectors large enough.
+// Chunks larger than 1 MiB are written to an external .mcc file.
+func (r *File) WriteChunkData(x, z int, data []byte, c Compression) (err error) {
+	var payload []byte
+
+	if 5+len(data) > maxSectorCount*SectorSize {
+		var path string
+
+		if path, err = r.externalPath(x, z); err != nil {
+			return
+		}
+
+		if err = os.WriteFile(path, data, 0644); err != nil {
+			return
+		}
+
+		payload = []byte{0, 0, 0, 1, byte(c) | externalFlag}
+	} else {
+		if err = r.removeExternal(x, z); err != nil {
+			return
+		}
+
+		payload = make([]byte, 5, 5+len(data))
+
+		binary.BigEndian.PutUint32(payload, uint32(len(data)+1))
+		payload[4] = byte(c)
+		payload = append(payload, data...)
+	}
+
+	count := (len(payload) + SectorSize - 1) / SectorSize
+
+	payload = append(payload, make([]byte, count*SectorSize-len(payload))...)
+
+	offset := r.allocate(x, z, count)
+
+	if _, err = r.f.WriteAt(payload, int64(offset)*SectorSize); err != nil {
+		return
+	}
+
+	i := chunkIndex(x, z)
+
+	r.locations[i] = uint32(offset<<8 | count)
+	r.timestamps[i] = uint32(time.Now().Unix())
+
+	return r.writeHeaderEntry(i)
+}
+
+// DeleteChunk removes the chunk at x, z. Its sectors are reused by later writes or dropped by Compact.
+func (r *File) DeleteChunk(x, z int) (err error) {
+	if err = r.removeExternal(x, z); err != nil {
+		return
+	}
+
+	i := chunkIndex(x, z)
+
+	r.locations[i] = 0
+	r.timestamps[i] = 0
+
+	return r.writeHeaderEntry(i)
+}
+
+// removeExternal deletes the .mcc file of the chunk at x, z, if there is one.
+func (r *File) removeExternal(x, z int) (err error) {
+	if !r.hasPos {
+		return
+	}
+
+	path, err := r.externalPath(x, z)
+
+	if err != nil {
+		return
+	}
+
+	if err = os.Remove(path); errors.Is(err, os.ErrNotExist) {
+		err = nil
+	}
+
+	return
+}
+
+// usedSectors marks the sectors occupied by the header and all chunks except the one at index skip.
+func (r *File) usedSectors(skip int) (used []bool) {
+	used = []bool{true, true}
+
+	for i, loc := range r.locations {
+		offset, count := int(loc>>8), int(loc&0xff)
+
+		if i == skip || offset < 2 {
+			continue
+		}
+
+		for len(used) < offset+count {
+			used = append(used, false)
+		}
+
+		for s := offset; s < offset+count; s++ {
+			used[s] = true
+		}
+	}
+
+	return
+}
+
+// allocate returns the first sector of count free sectors for the chunk at x, z.
+func (r *File) allocate(x, z int, count int) int {
+	oldOffset, oldCount := r.location(x, z)
+
+	if oldOffset >= 2 && count <= oldCount {
+		return oldOffset
+	}
+
+	used := r.usedSectors(chunkIndex(x, z))
+	run := 0
+
+	for s := 2; s < len(used); s++ {
+		if used[s] {
+			run = 0
+			continue
+		}
+
+		run++
+
+		if run == count {
+			return s - count + 1
+		}
+	}
+
+	// a free run at the end is extended past the end of the file
+	return len(used) - run
+}
+
+func (r *File) writeHeaderEntry(i int) (err error) {
+	entry := make([]byte, 4)
+
+	binary.BigEndian.PutUint32(entry, r.locations[i])
+
+	if _, err = r.f.WriteAt(entry, int64(i*4)); err != nil {
+		return
+	}
+
+	binary.BigEndian.PutUint32(entry, r.timestamps[i])
+
+	_, err = r.f.WriteAt(entry, int64(SectorSize+i*4))
+
+	return
+}
+
+func (r *File) writeHeader() (err error) {
+	header := make([]byte, headerSize)
+
+	for i := range ChunksPerRegion {
+		binary.BigEndian.PutUint32(header[i*4:], r.locations[i])
+		binary.BigEndian.PutUint32(header[SectorSize+i*4:], r.timestamps[i])
+	}
+
+	_, err = r.f.WriteAt(header, 0)
+
+	return
+}
+
+// Compact moves all chunks to the front of the file, closing the gaps left by moved and deleted chunks, and
+// truncates the file after the last chunk.
+func (r *File) Compact() (err error) {
+	indices := make([]int, 0, ChunksPerRegion)
+
+	for i, loc := range r.locations {
+		if loc>>8 >= 2 {
+			indices = append(indices, i)
+		}
+	}
+
+	slices.SortFunc(indices, func(a, b int) int {
+		return int(r.locations[a]>>8) - int(r.locations[b]>>8)
+	})
+
+	next := 2
+
+	for _, i := range indices {
+		offset, count := int(r.locations[i]>>8), int(r.locations[i]&0xff)
+
+		if offset != next {
+			// chunks are processed in order of their offset, so the target never overlaps a chunk still to be moved
+			buf := make([]byte, count*SectorSize)
+
+			if _, err = r.f.ReadAt(buf, int64(offset)*SectorSize); err != nil && !errors.Is(err, io.EOF) {
+				return
+			}
+
+			if _, err = r.f.WriteAt(buf, int64(next)*SectorSize); err != nil {
+				return
+			}
+
+			r.locations[i] = uint32(next<<8 | count)
+		}
+
+		next += count
+	}
+
+	if err = r.writeHeader(); err != nil {
+		return
+	}
+
+	return r.f.Truncate(int64(next) * SectorSize)
+}
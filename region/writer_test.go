@@ -0,0 +1,169 @@
+package region
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+func chunkTag(x, z int32, padding int) *nbt.Tag {
+	return &nbt.Tag{
+		Type: nbt.TypeCompound,
+		Name: []byte{},
+		Value: nbt.Compound{
+			"xPos":     {Type: nbt.TypeInt, Name: []byte("xPos"), Value: x},
+			"zPos":     {Type: nbt.TypeInt, Name: []byte("zPos"), Value: z},
+			"entities": {Type: nbt.TypeList, Name: []byte("entities"), Value: nbt.List{}},
+			"padding":  {Type: nbt.TypeByteArray, Name: []byte("padding"), Value: make([]byte, padding)},
+		},
+	}
+}
+
+func expectChunk(t *testing.T, r *File, x, z int, padding int) {
+	t.Helper()
+
+	tag, err := r.ReadChunk(x, z)
+
+	if err != nil {
+		t.Fatalf("chunk %d,%d: %s", x, z, err)
+	}
+
+	if xPos, _ := tag.Find("xPos"); xPos.Value != int32(x) {
+		t.Fatalf("chunk %d,%d: unexpected xPos %v", x, z, xPos.Value)
+	}
+
+	if p, _ := tag.Find("padding"); len(p.Value.([]byte)) != padding {
+		t.Fatalf("chunk %d,%d: expected %d bytes padding, got %d", x, z, padding, len(p.Value.([]byte)))
+	}
+}
+
+func TestWriteRegion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName(0, 0))
+	start := time.Now().Add(-time.Second)
+
+	r, err := OpenWrite(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compressions := []Compression{CompressionGzip, CompressionZlib, CompressionNone, CompressionLZ4}
+
+	for i, c := range compressions {
+		if err := r.WriteChunk(i, 0, chunkTag(int32(i), 0, 5000), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// chunk 2 is uncompressed, growing it forces a move behind chunk 3
+	if err := r.WriteChunk(2, 0, chunkTag(2, 0, 20000), CompressionNone); err != nil {
+		t.Fatal(err)
+	}
+
+	offset, count := r.location(2, 0)
+
+	if count != 5 || offset <= 4 {
+		t.Fatalf("expected chunk to be moved to the end, got offset %d, count %d", offset, count)
+	}
+
+	// the sectors left behind are reused
+	if err := r.WriteChunk(5, 0, chunkTag(5, 0, 10), CompressionNone); err != nil {
+		t.Fatal(err)
+	}
+
+	if offset, _ := r.location(5, 0); offset != 4 {
+		t.Fatalf("expected free sector 4 to be reused, got %d", offset)
+	}
+
+	// oversized chunks go to an external file
+	if err := r.WriteChunk(31, 31, chunkTag(31, 31, 2<<20), CompressionNone); err != nil {
+		t.Fatal(err)
+	}
+
+	mcc := filepath.Join(dir, "c.31.31.mcc")
+
+	if _, err := os.Stat(mcc); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = OpenWrite(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(r *File) {
+		_ = r.Close()
+	}(r)
+
+	expectChunk(t, r, 0, 0, 5000)
+	expectChunk(t, r, 1, 0, 5000)
+	expectChunk(t, r, 2, 0, 20000)
+	expectChunk(t, r, 3, 0, 5000)
+	expectChunk(t, r, 5, 0, 10)
+	expectChunk(t, r, 31, 31, 2<<20)
+
+	if r.Timestamp(2, 0).Before(start) {
+		t.Fatalf("expected timestamp to be updated, got %s", r.Timestamp(2, 0))
+	}
+
+	// shrinking the chunk brings it back into the region file
+	if err := r.WriteChunk(31, 31, chunkTag(31, 31, 10), CompressionZlib); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(mcc); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected %s to be removed, got %v", mcc, err)
+	}
+
+	if err := r.DeleteChunk(1, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := os.Stat(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Compact(); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := os.Stat(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if after.Size() >= before.Size() || after.Size()%SectorSize != 0 {
+		t.Fatalf("expected compacted file, size went from %d to %d", before.Size(), after.Size())
+	}
+
+	if r.HasChunk(1, 0) {
+		t.Fatal("expected chunk 1,0 to be deleted")
+	}
+
+	expectChunk(t, r, 0, 0, 5000)
+	expectChunk(t, r, 2, 0, 20000)
+	expectChunk(t, r, 3, 0, 5000)
+	expectChunk(t, r, 5, 0, 10)
+	expectChunk(t, r, 31, 31, 10)
+
+	used := r.usedSectors(-1)
+
+	for s, u := range used {
+		if !u {
+			t.Fatalf("expected no gaps after compaction, sector %d is free", s)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package schematic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/nbt"
+	"github.com/nitwhiz/go-nbt/volume"
+)
+
+// legacyPrefix is the namespace of states standing for numeric block ids.
+const legacyPrefix = "legacy:"
+
+// LegacyState returns the state standing for a numeric block id and data value of an MCEdit schematic, e.g.
+// legacy:35[data=14] for red wool. Use volume.Volume.MapStates to translate them into real block states. Only
+// these states and air can be written to MCEdit schematics.
+func LegacyState(id uint16, data uint8) block.State {
+	return block.State{
+		Name:       legacyPrefix + strconv.Itoa(int(id)),
+		Properties: map[string]string{"data": strconv.Itoa(int(data))},
+	}
+}
+
+// ParseLegacyState returns the numeric block id and data value of a state created by LegacyState.
+func ParseLegacyState(s block.State) (id uint16, data uint8, ok bool) {
+	idStr, found := strings.CutPrefix(s.Name, legacyPrefix)
+
+	if !found {
+		return
+	}
+
+	i, err := strconv.ParseUint(idStr, 10, 12)
+
+	if err != nil {
+		return
+	}
+
+	d := uint64(0)
+
+	if str, found := s.Properties["data"]; found {
+		if d, err = strconv.ParseUint(str, 10, 4); err != nil {
+			return
+		}
+	}
+
+	return uint16(i), uint8(d), true
+}
+
+func readMCEdit(c nbt.Compound) (s *Schematic, err error) {
+	sizeX, sizeY, sizeZ, err := size(c)
+
+	if err != nil {
+		return
+	}
+
+	s = &Schematic{Format: FormatMCEdit, Volume: volume.New(sizeX, sizeY, sizeZ)}
+
+	ids, _ := get[[]byte](c, "Blocks")
+	data, _ := get[[]byte](c, "Data")
+	add, _ := get[[]byte](c, "AddBlocks")
+
+	if len(ids) < len(s.Volume.Indices) || len(data) < len(s.Volume.Indices) {
+		return nil, errors.New("schematic: Blocks or Data too short")
+	}
+
+	s.Volume.Palette = nil
+
+	for i := range s.Volume.Indices {
+		id := uint16(ids[i])
+
+		// AddBlocks holds the upper 4 bits of the ids, two per byte
+		if i>>1 < len(add) {
+			if i&1 == 0 {
+				id |= uint16(add[i>>1]&0x0f) << 8
+			} else {
+				id |= uint16(add[i>>1]&0xf0) << 4
+			}
+		}
+
+		s.Volume.Indices[i] = s.Volume.StateIndex(LegacyState(id, data[i]&0x0f))
+	}
+
+	offset := [3]string{"WEOffsetX", "WEOffsetY", "WEOffsetZ"}
+
+	for i, name := range offset {
+		if v, ok := get[int32](c, name); ok {
+			s.Offset[i] = int(v)
+		}
+	}
+
+	tileEntities, _ := get[nbt.List](c, "TileEntities")
+
+	for _, tag := range tileEntities {
+		te, _ := tag.Value.(nbt.Compound)
+
+		x, okX := get[int32](te, "x")
+		y, okY := get[int32](te, "y")
+		z, okZ := get[int32](te, "z")
+
+		if !okX || !okY || !okZ {
+			return nil, errors.New("schematic: tile entity without position")
+		}
+
+		s.Volume.BlockEntities[[3]int{int(x), int(y), int(z)}] = without(te, "x", "y", "z")
+	}
+
+	entities, _ := get[nbt.List](c, "Entities")
+
+	for _, tag := range entities {
+		e, _ := tag.Value.(nbt.Compound)
+		pos, err := readDoubles(e, "Pos")
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.Volume.Entities = append(s.Volume.Entities, volume.Entity{Pos: pos, NBT: without(e, "Pos")})
+	}
+
+	return
+}
+
+func (s *Schematic) mcEdit() (root *nbt.Tag, err error) {
+	c := nbt.Compound{
+		"Materials": newTag(nbt.TypeString, "Materials", "Alpha"),
+	}
+
+	if err = s.sizeTags(c); err != nil {
+		return
+	}
+
+	n := len(s.Volume.Indices)
+	ids := make([]byte, n)
+	data := make([]byte, n)
+	add := make([]byte, (n+1)/2)
+	hasAdd := false
+
+	for i, index := range s.Volume.Indices {
+		id, d, ok := ParseLegacyState(s.Volume.Palette[index])
+
+		if !ok && s.Volume.Palette[index].Equal(block.Air) {
+			id, d, ok = 0, 0, true
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("schematic: %s has no numeric id, map it with LegacyState first", s.Volume.Palette[index])
+		}
+
+		ids[i] = byte(id)
+		data[i] = d
+
+		if id > 0xff {
+			hasAdd = true
+
+			if i&1 == 0 {
+				add[i>>1] |= byte(id>>8) & 0x0f
+			} else {
+				add[i>>1] |= byte(id>>4) & 0xf0
+			}
+		}
+	}
+
+	c["Blocks"] = newTag(nbt.TypeByteArray, "Blocks", ids)
+	c["Data"] = newTag(nbt.TypeByteArray, "Data", data)
+
+	if hasAdd {
+		c["AddBlocks"] = newTag(nbt.TypeByteArray, "AddBlocks", add)
+	}
+
+	for i, name := range [3]string{"WEOffsetX", "WEOffsetY", "WEOffsetZ"} {
+		c[name] = newTag(nbt.TypeInt, name, int32(s.Offset[i]))
+	}
+
+	tileEntities := nbt.List{}
+
+	for _, pos := range s.sortedBlockEntities() {
+		te := without(s.Volume.BlockEntities[pos])
+
+		for i, name := range [3]string{"x", "y", "z"} {
+			te[name] = newTag(nbt.TypeInt, name, int32(pos[i]))
+		}
+
+		tileEntities = append(tileEntities, &nbt.Tag{Type: nbt.TypeCompound, Value: te})
+	}
+
+	c["TileEntities"] = newTag(nbt.TypeList, "TileEntities", tileEntities)
+
+	entities := nbt.List{}
+
+	for _, e := range s.Volume.Entities {
+		data := without(e.NBT)
+
+		data["Pos"] = doubles(e.Pos, "Pos")
+
+		entities = append(entities, &nbt.Tag{Type: nbt.TypeCompound, Value: data})
+	}
+
+	c["Entities"] = newTag(nbt.TypeList, "Entities", entities)
+
+	return newTag(nbt.TypeCompound, "Schematic", c), nil
+}
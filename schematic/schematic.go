@@ -0,0 +1,192 @@
+package schematic
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+	"github.com/nitwhiz/go-nbt/volume"
+)
+
+// Format is the file format of a schematic.
+type Format int
+
+const (
+	// FormatSpongeV2 is version 2 of the Sponge schematic format (.schem), reading also accepts version 1.
+	FormatSpongeV2 Format = iota
+	// FormatSpongeV3 is version 3 of the Sponge schematic format (.schem), used by WorldEdit since 7.3.
+	FormatSpongeV3
+	// FormatMCEdit is the legacy MCEdit format (.schematic) with numeric block ids.
+	FormatMCEdit
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatSpongeV2:
+		return "sponge v2"
+	case FormatSpongeV3:
+		return "sponge v3"
+	case FormatMCEdit:
+		return "mcedit"
+	default:
+		return "unknown"
+	}
+}
+
+// Schematic is a schematic file. Biomes are not read.
+type Schematic struct {
+	Format Format
+	// DataVersion is the data version of the blocks, it's 0 for MCEdit schematics.
+	DataVersion int32
+	// Offset is the position of the volume relative to the player who copied it.
+	Offset   [3]int
+	Metadata nbt.Compound
+	Volume   *volume.Volume
+}
+
+// Read reads a schematic in any of the supported formats, gzip compressed or not.
+func Read(r io.Reader) (s *Schematic, err error) {
+	root := &nbt.Tag{}
+
+	if err = nbt.NewDecoder(r).Decode(root); err != nil {
+		return
+	}
+
+	c, ok := root.Value.(nbt.Compound)
+
+	if !ok {
+		return nil, errors.New("schematic: root is not a compound")
+	}
+
+	// version 3 wraps everything in a Schematic compound inside an unnamed root
+	if inner, ok := get[nbt.Compound](c, "Schematic"); ok {
+		c = inner
+	}
+
+	version, hasVersion := get[int32](c, "Version")
+
+	switch {
+	case hasVersion && version == 3:
+		return readSpongeV3(c)
+	case hasVersion && (version == 1 || version == 2):
+		return readSpongeV2(c)
+	case hasVersion:
+		return nil, fmt.Errorf("schematic: unsupported sponge version %d", version)
+	default:
+		if _, ok := get[[]byte](c, "Blocks"); ok {
+			return readMCEdit(c)
+		}
+
+		return nil, errors.New("schematic: unknown format")
+	}
+}
+
+// Write writes s gzip compressed in s.Format.
+func (s *Schematic) Write(w io.Writer) (err error) {
+	var root *nbt.Tag
+
+	switch s.Format {
+	case FormatSpongeV2:
+		root, err = s.spongeV2()
+	case FormatSpongeV3:
+		root, err = s.spongeV3()
+	case FormatMCEdit:
+		root, err = s.mcEdit()
+	default:
+		err = fmt.Errorf("schematic: unknown format %d", s.Format)
+	}
+
+	if err != nil {
+		return
+	}
+
+	return nbt.MarshalWriter(w, root, nbt.WithCompression(nbt.CompressionGzip))
+}
+
+// get returns the value of the entry name in c if it has type T.
+func get[T any](c nbt.Compound, name string) (v T, ok bool) {
+	tag, ok := c[name]
+
+	if !ok {
+		return
+	}
+
+	v, ok = tag.Value.(T)
+
+	return
+}
+
+func newTag(typ int, name string, value any) *nbt.Tag {
+	return &nbt.Tag{Type: typ, Name: []byte(name), Value: value}
+}
+
+// size reads the dimensions, which are stored as shorts but are unsigned.
+func size(c nbt.Compound) (sizeX, sizeY, sizeZ int, err error) {
+	width, okX := get[int16](c, "Width")
+	height, okY := get[int16](c, "Height")
+	length, okZ := get[int16](c, "Length")
+
+	if !okX || !okY || !okZ {
+		return 0, 0, 0, errors.New("schematic: missing Width, Height or Length")
+	}
+
+	return int(uint16(width)), int(uint16(height)), int(uint16(length)), nil
+}
+
+func (s *Schematic) sizeTags(c nbt.Compound) error {
+	for i, name := range []string{"Width", "Height", "Length"} {
+		if s.Volume.Size[i] > 0xffff {
+			return fmt.Errorf("schematic: %s %d exceeds 65535", name, s.Volume.Size[i])
+		}
+
+		c[name] = newTag(nbt.TypeShort, name, int16(uint16(s.Volume.Size[i])))
+	}
+
+	return nil
+}
+
+// without returns a copy of c without the given entries.
+func without(c nbt.Compound, names ...string) nbt.Compound {
+	res := make(nbt.Compound, len(c))
+
+	for name, tag := range c {
+		res[name] = tag
+	}
+
+	for _, name := range names {
+		delete(res, name)
+	}
+
+	return res
+}
+
+func doubles(pos [3]float64, name string) *nbt.Tag {
+	l := make(nbt.List, 3)
+
+	for i, v := range pos {
+		l[i] = &nbt.Tag{Type: nbt.TypeDouble, Value: v}
+	}
+
+	return newTag(nbt.TypeList, name, l)
+}
+
+func readDoubles(c nbt.Compound, name string) (pos [3]float64, err error) {
+	l, _ := get[nbt.List](c, name)
+
+	if len(l) != 3 {
+		return pos, fmt.Errorf("schematic: %s has %d components, expected 3", name, len(l))
+	}
+
+	for i, item := range l {
+		v, ok := item.Value.(float64)
+
+		if !ok {
+			return pos, fmt.Errorf("schematic: %s is not a list of doubles", name)
+		}
+
+		pos[i] = v
+	}
+
+	return
+}
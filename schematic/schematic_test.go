@@ -0,0 +1,189 @@
+package schematic
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/nbt"
+	"github.com/nitwhiz/go-nbt/structure"
+	"github.com/nitwhiz/go-nbt/volume"
+)
+
+var stairs = block.State{Name: "minecraft:oak_stairs", Properties: map[string]string{"facing": "east", "half": "top"}}
+
+func testVolume() *volume.Volume {
+	v := volume.New(20, 3, 10)
+
+	// more than 127 states make the varint indices take two bytes
+	for i := range 200 {
+		v.Set(i%20, 1, i/20, block.State{Name: "minecraft:wool", Properties: map[string]string{"n": string(rune('a' + i%26)), "m": string(rune('a' + i/26))}})
+	}
+
+	v.Set(19, 2, 9, stairs)
+	v.BlockEntities[[3]int{0, 0, 0}] = nbt.Compound{
+		"id":   {Type: nbt.TypeString, Name: []byte("id"), Value: "minecraft:chest"},
+		"Lock": {Type: nbt.TypeString, Name: []byte("Lock"), Value: "key"},
+	}
+	v.Set(0, 0, 0, block.State{Name: "minecraft:chest"})
+	v.Entities = append(v.Entities, volume.Entity{Pos: [3]float64{1.5, 2, 3.5}, NBT: nbt.Compound{
+		"id": {Type: nbt.TypeString, Name: []byte("id"), Value: "minecraft:pig"},
+	}})
+
+	return v
+}
+
+func roundTrip(t *testing.T, s *Schematic) *Schematic {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+
+	if err := s.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Read(buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Format != s.Format {
+		t.Fatalf("expected format %s, got %s", s.Format, res.Format)
+	}
+
+	return res
+}
+
+func expectSameVolume(t *testing.T, expected, actual *volume.Volume) {
+	t.Helper()
+
+	if expected.Size != actual.Size {
+		t.Fatalf("expected size %v, got %v", expected.Size, actual.Size)
+	}
+
+	for i := range expected.Indices {
+		x, y, z := expected.Pos(i)
+
+		if !expected.At(x, y, z).Equal(actual.At(x, y, z)) {
+			t.Fatalf("block %d,%d,%d: expected %s, got %s", x, y, z, expected.At(x, y, z), actual.At(x, y, z))
+		}
+	}
+
+	chest := actual.BlockEntities[[3]int{0, 0, 0}]
+
+	if chest["id"].Value != "minecraft:chest" || chest["Lock"].Value != "key" {
+		t.Fatalf("unexpected block entity %v", chest)
+	}
+
+	if len(actual.Entities) != 1 || actual.Entities[0].Pos != [3]float64{1.5, 2, 3.5} || actual.Entities[0].NBT["id"].Value != "minecraft:pig" {
+		t.Fatalf("unexpected entities %+v", actual.Entities)
+	}
+}
+
+func TestSponge(t *testing.T) {
+	for _, format := range []Format{FormatSpongeV2, FormatSpongeV3} {
+		s := &Schematic{Format: format, DataVersion: 3953, Offset: [3]int{-1, 0, 2}, Volume: testVolume()}
+
+		res := roundTrip(t, s)
+
+		if res.DataVersion != 3953 || res.Offset != s.Offset {
+			t.Fatalf("%s: unexpected data version %d or offset %v", format, res.DataVersion, res.Offset)
+		}
+
+		expectSameVolume(t, s.Volume, res.Volume)
+	}
+}
+
+func TestSpongeV3Layout(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	if err := (&Schematic{Format: FormatSpongeV3, Volume: testVolume()}).Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	root := &nbt.Tag{}
+
+	if err := nbt.Unmarshal(buf.Bytes(), root); err != nil {
+		t.Fatal(err)
+	}
+
+	s, ok := root.Find("Schematic")
+
+	if len(root.Name) != 0 || !ok {
+		t.Fatalf("expected Schematic compound inside an unnamed root")
+	}
+
+	blocks, _ := s.Find("Blocks")
+
+	if _, ok := blocks.Find("Palette"); !ok {
+		t.Fatalf("expected palette in Blocks")
+	}
+}
+
+func TestMCEdit(t *testing.T) {
+	v := volume.New(3, 2, 2)
+
+	v.Set(0, 0, 0, LegacyState(35, 14))
+	v.Set(1, 0, 0, LegacyState(1, 0))
+	v.Set(2, 1, 1, LegacyState(300, 2))
+	v.Set(0, 0, 0, LegacyState(54, 2))
+	v.BlockEntities[[3]int{0, 0, 0}] = nbt.Compound{
+		"id":   {Type: nbt.TypeString, Name: []byte("id"), Value: "minecraft:chest"},
+		"Lock": {Type: nbt.TypeString, Name: []byte("Lock"), Value: "key"},
+	}
+	v.Set(0, 0, 0, block.State{Name: "minecraft:chest"})
+
+	if err := (&Schematic{Format: FormatMCEdit, Volume: v}).Write(new(bytes.Buffer)); err == nil {
+		t.Fatalf("expected error for a state without numeric id")
+	}
+
+	v.Set(0, 0, 0, LegacyState(54, 2))
+	v.Entities = append(v.Entities, volume.Entity{Pos: [3]float64{1.5, 2, 3.5}, NBT: nbt.Compound{
+		"id": {Type: nbt.TypeString, Name: []byte("id"), Value: "Pig"},
+	}})
+
+	res := roundTrip(t, &Schematic{Format: FormatMCEdit, Offset: [3]int{1, 2, 3}, Volume: v})
+
+	if res.Offset != [3]int{1, 2, 3} {
+		t.Fatalf("unexpected offset %v", res.Offset)
+	}
+
+	if id, data, ok := ParseLegacyState(res.Volume.At(2, 1, 1)); !ok || id != 300 || data != 2 {
+		t.Fatalf("expected block 300:2, got %s", res.Volume.At(2, 1, 1))
+	}
+
+	if id, _, _ := ParseLegacyState(res.Volume.At(1, 1, 0)); id != 0 {
+		t.Fatalf("expected air, got %s", res.Volume.At(1, 1, 0))
+	}
+
+	if res.Volume.BlockEntities[[3]int{0, 0, 0}]["Lock"].Value != "key" || len(res.Volume.Entities) != 1 {
+		t.Fatalf("block entities or entities are missing")
+	}
+}
+
+func TestToStructure(t *testing.T) {
+	v := testVolume()
+
+	v.Set(5, 0, 5, structure.Void)
+
+	st := structure.FromVolume(v, 3953)
+
+	if _, ok := st.At(5, 0, 5); ok {
+		t.Fatalf("expected structure void to be left out")
+	}
+
+	if p, ok := st.At(19, 2, 9); !ok || !p.State.Equal(stairs) {
+		t.Fatalf("expected stairs, got %v", p.State)
+	}
+
+	if st.Entities[0].BlockPos != [3]int{1, 2, 3} {
+		t.Fatalf("unexpected entity block position %v", st.Entities[0].BlockPos)
+	}
+
+	res := st.Volume(0)
+
+	if !res.At(5, 0, 5).Equal(structure.Void) || !res.At(19, 2, 9).Equal(stairs) {
+		t.Fatalf("unexpected blocks after converting back")
+	}
+}
@@ -0,0 +1,338 @@
+package schematic
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/nbt"
+	"github.com/nitwhiz/go-nbt/volume"
+)
+
+// readSpongeHeader reads the entries shared by all Sponge versions.
+func readSpongeHeader(c nbt.Compound, format Format) (s *Schematic, err error) {
+	sizeX, sizeY, sizeZ, err := size(c)
+
+	if err != nil {
+		return
+	}
+
+	s = &Schematic{Format: format, Volume: volume.New(sizeX, sizeY, sizeZ)}
+
+	s.DataVersion, _ = get[int32](c, "DataVersion")
+	s.Metadata, _ = get[nbt.Compound](c, "Metadata")
+
+	if offset, ok := get[[]int32](c, "Offset"); ok && len(offset) == 3 {
+		s.Offset = [3]int{int(offset[0]), int(offset[1]), int(offset[2])}
+	}
+
+	return
+}
+
+// readBlocks resolves the varint encoded palette indices in data with a Sponge palette.
+func readBlocks(v *volume.Volume, palette nbt.Compound, data []byte) (err error) {
+	v.Palette = make([]block.State, len(palette))
+
+	for key, tag := range palette {
+		index, ok := tag.Value.(int32)
+
+		if !ok || index < 0 || int(index) >= len(palette) {
+			return fmt.Errorf("schematic: invalid palette index for %s", key)
+		}
+
+		if v.Palette[index], err = block.ParseState(key); err != nil {
+			return
+		}
+	}
+
+	for i := range v.Indices {
+		index, n := binary.Uvarint(data)
+
+		if n <= 0 {
+			return errors.New("schematic: block data too short")
+		}
+
+		if index >= uint64(len(v.Palette)) {
+			return fmt.Errorf("schematic: palette index %d out of range", index)
+		}
+
+		v.Indices[i] = int(index)
+		data = data[n:]
+	}
+
+	return
+}
+
+func (s *Schematic) writeBlocks() (palette nbt.Compound, data []byte) {
+	palette = nbt.Compound{}
+
+	// states used more than once in the volume palette share one index
+	remap := make([]int32, len(s.Volume.Palette))
+
+	for i, state := range s.Volume.Palette {
+		key := state.String()
+
+		if tag, ok := palette[key]; ok {
+			remap[i] = tag.Value.(int32)
+			continue
+		}
+
+		remap[i] = int32(len(palette))
+		palette[key] = newTag(nbt.TypeInt, key, remap[i])
+	}
+
+	data = make([]byte, 0, len(s.Volume.Indices))
+
+	for _, index := range s.Volume.Indices {
+		data = binary.AppendUvarint(data, uint64(remap[index]))
+	}
+
+	return
+}
+
+func readPos(c nbt.Compound) (pos [3]int, err error) {
+	p, ok := get[[]int32](c, "Pos")
+
+	if !ok || len(p) != 3 {
+		return pos, errors.New("schematic: block entity without Pos")
+	}
+
+	return [3]int{int(p[0]), int(p[1]), int(p[2])}, nil
+}
+
+// withID returns a copy of the data of a block entity or entity with its id stored in id, as used in game.
+func withID(data nbt.Compound, id string) nbt.Compound {
+	data = without(data, "Id")
+	data["id"] = newTag(nbt.TypeString, "id", id)
+
+	return data
+}
+
+func readSpongeV2(c nbt.Compound) (s *Schematic, err error) {
+	if s, err = readSpongeHeader(c, FormatSpongeV2); err != nil {
+		return
+	}
+
+	palette, _ := get[nbt.Compound](c, "Palette")
+	data, _ := get[[]byte](c, "BlockData")
+
+	if err = readBlocks(s.Volume, palette, data); err != nil {
+		return nil, err
+	}
+
+	blockEntities, ok := get[nbt.List](c, "BlockEntities")
+
+	if !ok {
+		// version 1
+		blockEntities, _ = get[nbt.List](c, "TileEntities")
+	}
+
+	for _, tag := range blockEntities {
+		be, _ := tag.Value.(nbt.Compound)
+		pos, err := readPos(be)
+
+		if err != nil {
+			return nil, err
+		}
+
+		id, _ := get[string](be, "Id")
+		s.Volume.BlockEntities[pos] = withID(without(be, "Pos"), id)
+	}
+
+	entities, _ := get[nbt.List](c, "Entities")
+
+	for _, tag := range entities {
+		e, _ := tag.Value.(nbt.Compound)
+		pos, err := readDoubles(e, "Pos")
+
+		if err != nil {
+			return nil, err
+		}
+
+		id, _ := get[string](e, "Id")
+		s.Volume.Entities = append(s.Volume.Entities, volume.Entity{Pos: pos, NBT: withID(without(e, "Pos"), id)})
+	}
+
+	return
+}
+
+func readSpongeV3(c nbt.Compound) (s *Schematic, err error) {
+	if s, err = readSpongeHeader(c, FormatSpongeV3); err != nil {
+		return
+	}
+
+	blocks, ok := get[nbt.Compound](c, "Blocks")
+
+	if !ok {
+		return
+	}
+
+	palette, _ := get[nbt.Compound](blocks, "Palette")
+	data, _ := get[[]byte](blocks, "Data")
+
+	if err = readBlocks(s.Volume, palette, data); err != nil {
+		return nil, err
+	}
+
+	blockEntities, _ := get[nbt.List](blocks, "BlockEntities")
+
+	for _, tag := range blockEntities {
+		be, _ := tag.Value.(nbt.Compound)
+		pos, err := readPos(be)
+
+		if err != nil {
+			return nil, err
+		}
+
+		id, _ := get[string](be, "Id")
+		beData, _ := get[nbt.Compound](be, "Data")
+		s.Volume.BlockEntities[pos] = withID(beData, id)
+	}
+
+	entities, _ := get[nbt.List](c, "Entities")
+
+	for _, tag := range entities {
+		e, _ := tag.Value.(nbt.Compound)
+		pos, err := readDoubles(e, "Pos")
+
+		if err != nil {
+			return nil, err
+		}
+
+		id, _ := get[string](e, "Id")
+		eData, _ := get[nbt.Compound](e, "Data")
+		s.Volume.Entities = append(s.Volume.Entities, volume.Entity{Pos: pos, NBT: withID(eData, id)})
+	}
+
+	return
+}
+
+// spongeHeader builds the entries shared by all Sponge versions.
+func (s *Schematic) spongeHeader(version int32) (c nbt.Compound, err error) {
+	c = nbt.Compound{
+		"Version":     newTag(nbt.TypeInt, "Version", version),
+		"DataVersion": newTag(nbt.TypeInt, "DataVersion", s.DataVersion),
+		"Offset":      newTag(nbt.TypeIntArray, "Offset", []int32{int32(s.Offset[0]), int32(s.Offset[1]), int32(s.Offset[2])}),
+	}
+
+	if s.Metadata != nil {
+		c["Metadata"] = newTag(nbt.TypeCompound, "Metadata", s.Metadata)
+	}
+
+	err = s.sizeTags(c)
+
+	return
+}
+
+// sortedBlockEntities returns the block entity positions in the order of the block data.
+func (s *Schematic) sortedBlockEntities() [][3]int {
+	positions := make([][3]int, 0, len(s.Volume.BlockEntities))
+
+	for pos := range s.Volume.BlockEntities {
+		positions = append(positions, pos)
+	}
+
+	slices.SortFunc(positions, func(a, b [3]int) int {
+		return s.Volume.Index(a[0], a[1], a[2]) - s.Volume.Index(b[0], b[1], b[2])
+	})
+
+	return positions
+}
+
+func posTag(pos [3]int) *nbt.Tag {
+	return newTag(nbt.TypeIntArray, "Pos", []int32{int32(pos[0]), int32(pos[1]), int32(pos[2])})
+}
+
+func (s *Schematic) spongeV2() (root *nbt.Tag, err error) {
+	c, err := s.spongeHeader(2)
+
+	if err != nil {
+		return
+	}
+
+	palette, data := s.writeBlocks()
+
+	c["PaletteMax"] = newTag(nbt.TypeInt, "PaletteMax", int32(len(palette)))
+	c["Palette"] = newTag(nbt.TypeCompound, "Palette", palette)
+	c["BlockData"] = newTag(nbt.TypeByteArray, "BlockData", data)
+
+	blockEntities := nbt.List{}
+
+	for _, pos := range s.sortedBlockEntities() {
+		be := s.Volume.BlockEntities[pos]
+		id, _ := get[string](be, "id")
+
+		be = without(be, "id")
+		be["Id"] = newTag(nbt.TypeString, "Id", id)
+		be["Pos"] = posTag(pos)
+
+		blockEntities = append(blockEntities, &nbt.Tag{Type: nbt.TypeCompound, Value: be})
+	}
+
+	c["BlockEntities"] = newTag(nbt.TypeList, "BlockEntities", blockEntities)
+
+	entities := nbt.List{}
+
+	for _, e := range s.Volume.Entities {
+		id, _ := get[string](e.NBT, "id")
+		data := without(e.NBT, "id")
+
+		data["Id"] = newTag(nbt.TypeString, "Id", id)
+		data["Pos"] = doubles(e.Pos, "Pos")
+
+		entities = append(entities, &nbt.Tag{Type: nbt.TypeCompound, Value: data})
+	}
+
+	c["Entities"] = newTag(nbt.TypeList, "Entities", entities)
+
+	return newTag(nbt.TypeCompound, "Schematic", c), nil
+}
+
+func (s *Schematic) spongeV3() (root *nbt.Tag, err error) {
+	c, err := s.spongeHeader(3)
+
+	if err != nil {
+		return
+	}
+
+	palette, data := s.writeBlocks()
+
+	blockEntities := nbt.List{}
+
+	for _, pos := range s.sortedBlockEntities() {
+		be := s.Volume.BlockEntities[pos]
+		id, _ := get[string](be, "id")
+
+		blockEntities = append(blockEntities, &nbt.Tag{Type: nbt.TypeCompound, Value: nbt.Compound{
+			"Id":   newTag(nbt.TypeString, "Id", id),
+			"Pos":  posTag(pos),
+			"Data": newTag(nbt.TypeCompound, "Data", without(be, "id")),
+		}})
+	}
+
+	c["Blocks"] = newTag(nbt.TypeCompound, "Blocks", nbt.Compound{
+		"Palette":       newTag(nbt.TypeCompound, "Palette", palette),
+		"Data":          newTag(nbt.TypeByteArray, "Data", data),
+		"BlockEntities": newTag(nbt.TypeList, "BlockEntities", blockEntities),
+	})
+
+	entities := nbt.List{}
+
+	for _, e := range s.Volume.Entities {
+		id, _ := get[string](e.NBT, "id")
+
+		entities = append(entities, &nbt.Tag{Type: nbt.TypeCompound, Value: nbt.Compound{
+			"Id":   newTag(nbt.TypeString, "Id", id),
+			"Pos":  doubles(e.Pos, "Pos"),
+			"Data": newTag(nbt.TypeCompound, "Data", without(e.NBT, "id")),
+		}})
+	}
+
+	c["Entities"] = newTag(nbt.TypeList, "Entities", entities)
+
+	return newTag(nbt.TypeCompound, "", nbt.Compound{
+		"Schematic": newTag(nbt.TypeCompound, "Schematic", c),
+	}), nil
+}
@@ -0,0 +1,326 @@
+package structure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+// Structure is a structure file as saved by structure blocks and used by jigsaw pools.
+type Structure struct {
+	DataVersion int32
+	// Size is the extent along x, y and z.
+	Size [3]int
+	// Palettes holds the block states, structures with variants (like shipwrecks) have more than one palette.
+	Palettes [][]block.State
+	// Blocks holds all blocks except structure voids, which are left out.
+	Blocks   []Block
+	Entities []Entity
+	Rest     nbt.Compound
+	index    map[[3]int]int
+}
+
+// Block is a block of a structure. State is an index into each palette.
+type Block struct {
+	Pos   [3]int
+	State int
+	NBT   nbt.Compound
+}
+
+// Entity is an entity of a structure. Pos is relative to the structure origin, BlockPos is the block containing it.
+type Entity struct {
+	Pos      [3]float64
+	BlockPos [3]int
+	NBT      nbt.Compound
+}
+
+// Placement is a block with its resolved state.
+type Placement struct {
+	Pos   [3]int
+	State block.State
+	NBT   nbt.Compound
+}
+
+// file is the NBT layout of a structure file.
+type file struct {
+	DataVersion int32        `nbt:"DataVersion"`
+	Size        []int32      `nbt:"size"`
+	Palette     nbt.List     `nbt:"palette,omitempty"`
+	Palettes    []nbt.List   `nbt:"palettes,omitempty"`
+	Blocks      []fileBlock  `nbt:"blocks"`
+	Entities    []fileEntity `nbt:"entities"`
+	Rest        nbt.Compound `nbt:",rest"`
+}
+
+type fileBlock struct {
+	State int32        `nbt:"state"`
+	Pos   []int32      `nbt:"pos"`
+	NBT   nbt.Compound `nbt:"nbt,omitempty"`
+}
+
+type fileEntity struct {
+	Pos      []float64    `nbt:"pos"`
+	BlockPos []int32      `nbt:"blockPos"`
+	NBT      nbt.Compound `nbt:"nbt"`
+}
+
+func vec3[T any](v []T, what string) (res [3]T, err error) {
+	if len(v) != 3 {
+		return res, fmt.Errorf("structure: %s has %d components, expected 3", what, len(v))
+	}
+
+	copy(res[:], v)
+
+	return
+}
+
+func intVec3(v []int32, what string) (res [3]int, err error) {
+	v32, err := vec3(v, what)
+
+	for i, n := range v32 {
+		res[i] = int(n)
+	}
+
+	return
+}
+
+// Read reads a structure file, gzip compressed or not.
+func Read(r io.Reader) (s *Structure, err error) {
+	tag := &nbt.Tag{}
+
+	if err = nbt.NewDecoder(r).Decode(tag); err != nil {
+		return
+	}
+
+	f := file{}
+
+	if err = nbt.UnmarshalTag(&f, tag); err != nil {
+		return
+	}
+
+	return fromFile(&f)
+}
+
+func fromFile(f *file) (s *Structure, err error) {
+	s = &Structure{DataVersion: f.DataVersion, Rest: f.Rest}
+
+	if s.Size, err = intVec3(f.Size, "size"); err != nil {
+		return nil, err
+	}
+
+	palettes := f.Palettes
+
+	if f.Palette != nil {
+		palettes = []nbt.List{f.Palette}
+	}
+
+	if len(palettes) == 0 {
+		return nil, errors.New("structure: no palette")
+	}
+
+	for _, p := range palettes {
+		// blocks index every palette, the variants only differ in their states
+		if len(p) != len(palettes[0]) {
+			return nil, fmt.Errorf("structure: palettes have different lengths: %d and %d", len(palettes[0]), len(p))
+		}
+
+		states := make([]block.State, len(p))
+
+		for i, entry := range p {
+			if states[i], err = block.StateFromTag(entry); err != nil {
+				return nil, err
+			}
+		}
+
+		s.Palettes = append(s.Palettes, states)
+	}
+
+	for _, b := range f.Blocks {
+		if b.State < 0 || int(b.State) >= len(s.Palettes[0]) {
+			return nil, fmt.Errorf("structure: palette index %d out of range", b.State)
+		}
+
+		pos, err := intVec3(b.Pos, "block position")
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.Blocks = append(s.Blocks, Block{Pos: pos, State: int(b.State), NBT: b.NBT})
+	}
+
+	for _, e := range f.Entities {
+		pos, err := vec3(e.Pos, "entity position")
+
+		if err != nil {
+			return nil, err
+		}
+
+		blockPos, err := intVec3(e.BlockPos, "entity block position")
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.Entities = append(s.Entities, Entity{Pos: pos, BlockPos: blockPos, NBT: e.NBT})
+	}
+
+	return
+}
+
+func (s *Structure) toFile() *file {
+	f := &file{
+		DataVersion: s.DataVersion,
+		Size:        []int32{int32(s.Size[0]), int32(s.Size[1]), int32(s.Size[2])},
+		Blocks:      make([]fileBlock, 0, len(s.Blocks)),
+		Entities:    make([]fileEntity, 0, len(s.Entities)),
+		Rest:        s.Rest,
+	}
+
+	palettes := make([]nbt.List, len(s.Palettes))
+
+	for i, p := range s.Palettes {
+		palettes[i] = make(nbt.List, len(p))
+
+		for j, state := range p {
+			palettes[i][j] = state.Tag()
+		}
+	}
+
+	if len(palettes) == 1 {
+		f.Palette = palettes[0]
+	} else {
+		f.Palettes = palettes
+	}
+
+	for _, b := range s.Blocks {
+		f.Blocks = append(f.Blocks, fileBlock{
+			State: int32(b.State),
+			Pos:   []int32{int32(b.Pos[0]), int32(b.Pos[1]), int32(b.Pos[2])},
+			NBT:   b.NBT,
+		})
+	}
+
+	for _, e := range s.Entities {
+		nbtCompound := e.NBT
+
+		if nbtCompound == nil {
+			nbtCompound = nbt.Compound{}
+		}
+
+		f.Entities = append(f.Entities, fileEntity{
+			Pos:      e.Pos[:],
+			BlockPos: []int32{int32(e.BlockPos[0]), int32(e.BlockPos[1]), int32(e.BlockPos[2])},
+			NBT:      nbtCompound,
+		})
+	}
+
+	return f
+}
+
+// Write writes s as gzip compressed structure file, like the game does.
+func (s *Structure) Write(w io.Writer) error {
+	return nbt.MarshalWriter(w, &struct {
+		Root *file `nbt:""`
+	}{s.toFile()}, nbt.WithCompression(nbt.CompressionGzip))
+}
+
+// At returns the block at x, y, z with its state from the first palette. ok is false for structure voids and
+// positions outside the structure. Blocks are indexed on the first call, later changes to Blocks are not seen.
+func (s *Structure) At(x, y, z int) (p Placement, ok bool) {
+	if s.index == nil {
+		s.index = make(map[[3]int]int, len(s.Blocks))
+
+		for i, b := range s.Blocks {
+			s.index[b.Pos] = i
+		}
+	}
+
+	i, ok := s.index[[3]int{x, y, z}]
+
+	if !ok {
+		return
+	}
+
+	return s.placement(s.Blocks[i], 0), true
+}
+
+func (s *Structure) placement(b Block, palette int) Placement {
+	return Placement{Pos: b.Pos, State: s.Palettes[palette][b.State], NBT: b.NBT}
+}
+
+// Placements iterates over all blocks with their states from the given palette.
+func (s *Structure) Placements(palette int) iter.Seq[Placement] {
+	return func(yield func(Placement) bool) {
+		for _, b := range s.Blocks {
+			if !yield(s.placement(b, palette)) {
+				return
+			}
+		}
+	}
+}
+
+// Build creates a structure with a single palette from placements. Positions may be anywhere, they are shifted so
+// the smallest coordinates become the origin and entities are moved along. Later placements at the same position
+// replace earlier ones.
+func Build(dataVersion int32, placements []Placement, entities []Entity) *Structure {
+	s := &Structure{DataVersion: dataVersion, Palettes: [][]block.State{{}}}
+
+	if len(placements) == 0 {
+		s.Entities = entities
+
+		return s
+	}
+
+	lo, hi := placements[0].Pos, placements[0].Pos
+
+	for _, p := range placements {
+		for i := range 3 {
+			lo[i] = min(lo[i], p.Pos[i])
+			hi[i] = max(hi[i], p.Pos[i])
+		}
+	}
+
+	for i := range 3 {
+		s.Size[i] = hi[i] - lo[i] + 1
+	}
+
+	states := map[string]int{}
+	blocks := map[[3]int]int{}
+
+	for _, p := range placements {
+		key := p.State.String()
+		state, ok := states[key]
+
+		if !ok {
+			state = len(s.Palettes[0])
+			states[key] = state
+			s.Palettes[0] = append(s.Palettes[0], p.State)
+		}
+
+		pos := [3]int{p.Pos[0] - lo[0], p.Pos[1] - lo[1], p.Pos[2] - lo[2]}
+		b := Block{Pos: pos, State: state, NBT: p.NBT}
+
+		if i, ok := blocks[pos]; ok {
+			s.Blocks[i] = b
+		} else {
+			blocks[pos] = len(s.Blocks)
+			s.Blocks = append(s.Blocks, b)
+		}
+	}
+
+	for _, e := range entities {
+		for i := range 3 {
+			e.Pos[i] -= float64(lo[i])
+			e.BlockPos[i] -= lo[i]
+		}
+
+		s.Entities = append(s.Entities, e)
+	}
+
+	return s
+}
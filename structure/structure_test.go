@@ -0,0 +1,150 @@
+package structure
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+var (
+	stone = block.State{Name: "minecraft:stone"}
+	chest = block.State{Name: "minecraft:chest", Properties: map[string]string{"facing": "north"}}
+)
+
+func testStructure() *Structure {
+	placements := []Placement{
+		{Pos: [3]int{10, 64, -5}, State: stone},
+		{Pos: [3]int{11, 64, -5}, State: stone},
+		{Pos: [3]int{12, 66, -3}, State: chest, NBT: nbt.Compound{
+			"id": {Type: nbt.TypeString, Name: []byte("id"), Value: "minecraft:chest"},
+		}},
+		{Pos: [3]int{11, 64, -5}, State: block.Air},
+	}
+
+	entities := []Entity{
+		{Pos: [3]float64{10.5, 65, -4.5}, BlockPos: [3]int{10, 65, -5}, NBT: nbt.Compound{
+			"id": {Type: nbt.TypeString, Name: []byte("id"), Value: "minecraft:pig"},
+		}},
+	}
+
+	return Build(3953, placements, entities)
+}
+
+func TestBuild(t *testing.T) {
+	s := testStructure()
+
+	if s.Size != [3]int{3, 3, 3} {
+		t.Fatalf("expected size 3x3x3, got %v", s.Size)
+	}
+
+	if len(s.Blocks) != 3 || len(s.Palettes[0]) != 3 {
+		t.Fatalf("expected 3 blocks and 3 states, got %d and %d", len(s.Blocks), len(s.Palettes[0]))
+	}
+
+	if p, ok := s.At(1, 0, 0); !ok || !p.State.Equal(block.Air) {
+		t.Fatalf("expected replaced block to be air, got %v", p.State)
+	}
+
+	if s.Entities[0].Pos != [3]float64{0.5, 1, 0.5} || s.Entities[0].BlockPos != [3]int{0, 1, 0} {
+		t.Fatalf("expected entity to be moved with the blocks, got %+v", s.Entities[0])
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	if err := testStructure().Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, _, _ := nbt.DetectCompression(bytes.NewReader(buf.Bytes())); c != nbt.CompressionGzip {
+		t.Fatalf("expected gzip compression, got %s", c)
+	}
+
+	s, err := Read(buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, ok := s.At(2, 2, 2)
+
+	if !ok || !p.State.Equal(chest) || p.NBT["id"].Value != "minecraft:chest" {
+		t.Fatalf("expected chest with block entity, got %+v", p)
+	}
+
+	if _, ok := s.At(0, 1, 0); ok {
+		t.Fatalf("expected no block at 0,1,0")
+	}
+
+	var names []string
+
+	for p := range s.Placements(0) {
+		names = append(names, p.State.Name)
+	}
+
+	if !slices.Equal(names, []string{"minecraft:stone", "minecraft:air", "minecraft:chest"}) {
+		t.Fatalf("unexpected placements %v", names)
+	}
+
+	if len(s.Entities) != 1 || s.Entities[0].NBT["id"].Value != "minecraft:pig" {
+		t.Fatalf("unexpected entities %+v", s.Entities)
+	}
+}
+
+func TestPalettes(t *testing.T) {
+	s := testStructure()
+
+	variant := slices.Clone(s.Palettes[0])
+	variant[0] = block.State{Name: "minecraft:cobblestone"}
+	s.Palettes = append(s.Palettes, variant)
+
+	buf := new(bytes.Buffer)
+
+	if err := s.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	tag := &nbt.Tag{}
+
+	if err := nbt.Unmarshal(buf.Bytes(), tag); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := tag.Find("palette"); ok {
+		t.Fatalf("expected palettes instead of palette")
+	}
+
+	res, err := Read(buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Palettes) != 2 {
+		t.Fatalf("expected 2 palettes, got %d", len(res.Palettes))
+	}
+
+	for p := range res.Placements(1) {
+		if p.State.Name != "minecraft:cobblestone" {
+			t.Fatalf("expected cobblestone from the second palette, got %s", p.State.Name)
+		}
+
+		break
+	}
+
+	s.Palettes[1] = variant[:1]
+
+	buf.Reset()
+
+	if err := s.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Read(buf); err == nil {
+		t.Fatalf("expected error for palettes of different lengths")
+	}
+}
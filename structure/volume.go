@@ -0,0 +1,76 @@
+package structure
+
+import (
+	"math"
+
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/volume"
+)
+
+// Void is the state of structure voids, positions a structure leaves untouched when placed.
+var Void = block.State{Name: "minecraft:structure_void"}
+
+// FromVolume creates a structure with a single palette from v. Structure voids are left out.
+func FromVolume(v *volume.Volume, dataVersion int32) *Structure {
+	s := &Structure{DataVersion: dataVersion, Size: v.Size, Palettes: [][]block.State{{}}}
+
+	remap := make([]int, len(v.Palette))
+
+	for i, state := range v.Palette {
+		remap[i] = -1
+
+		if !state.Equal(Void) {
+			remap[i] = len(s.Palettes[0])
+			s.Palettes[0] = append(s.Palettes[0], state)
+		}
+	}
+
+	for i, index := range v.Indices {
+		if remap[index] == -1 {
+			continue
+		}
+
+		x, y, z := v.Pos(i)
+		pos := [3]int{x, y, z}
+
+		s.Blocks = append(s.Blocks, Block{Pos: pos, State: remap[index], NBT: v.BlockEntities[pos]})
+	}
+
+	for _, e := range v.Entities {
+		blockPos := [3]int{}
+
+		for i, p := range e.Pos {
+			blockPos[i] = int(math.Floor(p))
+		}
+
+		s.Entities = append(s.Entities, Entity{Pos: e.Pos, BlockPos: blockPos, NBT: e.NBT})
+	}
+
+	return s
+}
+
+// Volume converts s into a volume using the states of the given palette. Positions without a block become
+// structure voids.
+func (s *Structure) Volume(palette int) *volume.Volume {
+	v := volume.New(s.Size[0], s.Size[1], s.Size[2])
+
+	v.Palette[0] = Void
+
+	for _, b := range s.Blocks {
+		if !v.Contains(b.Pos[0], b.Pos[1], b.Pos[2]) {
+			continue
+		}
+
+		v.Set(b.Pos[0], b.Pos[1], b.Pos[2], s.Palettes[palette][b.State])
+
+		if b.NBT != nil {
+			v.BlockEntities[b.Pos] = b.NBT
+		}
+	}
+
+	for _, e := range s.Entities {
+		v.Entities = append(v.Entities, volume.Entity{Pos: e.Pos, NBT: e.NBT})
+	}
+
+	return v
+}
@@ -0,0 +1,11 @@
+#!/bin/sh
+# Vendors goleveldb, patches in Mojang's zlib block compression and writes ../bedrock.
+set -e
+
+cd "$(dirname "$0")"
+
+rm -rf vendor
+go mod vendor
+patch -d vendor/github.com/syndtr/goleveldb -p1 < mojang-zlib.patch
+go run -mod=vendor .
+rm -rf vendor
@@ -0,0 +1,187 @@
+// Command bedrock-gen writes the test world in ../bedrock. The database is written by goleveldb, a LevelDB
+// implementation independent of the reader under test, and keys and NBT values are encoded by hand following the
+// Bedrock Edition formats. goleveldb only knows snappy compression, mojang-zlib.patch adds the zlib (2) and
+// zlib-raw (4) block types of Mojang's LevelDB fork to a vendored copy. Run it with ./gen.sh from this directory.
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const world = "../bedrock"
+
+// compound encodes a little endian root compound, entries are added by the methods.
+type compound struct {
+	bytes.Buffer
+}
+
+func newCompound() *compound {
+	c := &compound{}
+
+	c.header(10, "")
+
+	return c
+}
+
+func (c *compound) header(typ byte, name string) {
+	c.WriteByte(typ)
+	_ = binary.Write(c, binary.LittleEndian, uint16(len(name)))
+	c.WriteString(name)
+}
+
+func (c *compound) str(name string, v string) *compound {
+	c.header(8, name)
+	_ = binary.Write(c, binary.LittleEndian, uint16(len(v)))
+	c.WriteString(v)
+
+	return c
+}
+
+func (c *compound) int(name string, v int32) *compound {
+	c.header(3, name)
+	_ = binary.Write(c, binary.LittleEndian, v)
+
+	return c
+}
+
+func (c *compound) long(name string, v int64) *compound {
+	c.header(4, name)
+	_ = binary.Write(c, binary.LittleEndian, v)
+
+	return c
+}
+
+func (c *compound) floats(name string, vs ...float32) *compound {
+	c.header(9, name)
+	c.WriteByte(5)
+	_ = binary.Write(c, binary.LittleEndian, int32(len(vs)))
+	_ = binary.Write(c, binary.LittleEndian, vs)
+
+	return c
+}
+
+func (c *compound) bytes() []byte {
+	return append(c.Bytes(), 0)
+}
+
+// chunkKey returns the key of a chunk record, dim 0 is left out like the game does for the Overworld.
+func chunkKey(x, z, dim int32, tag byte, extra ...byte) []byte {
+	k := binary.LittleEndian.AppendUint32(nil, uint32(x))
+	k = binary.LittleEndian.AppendUint32(k, uint32(z))
+
+	if dim != 0 {
+		k = binary.LittleEndian.AppendUint32(k, uint32(dim))
+	}
+
+	return append(append(k, tag), extra...)
+}
+
+// openDB opens the database of the world, tables are written with compression c. The small block size makes the
+// tables span several blocks.
+func openDB(c opt.Compression) *leveldb.DB {
+	db, err := leveldb.OpenFile(filepath.Join(world, "db"), &opt.Options{
+		Compression: c,
+		BlockSize:   256,
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return db
+}
+
+func closeDB(db *leveldb.DB) {
+	if err := db.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	if err := os.RemoveAll(world); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.MkdirAll(world, 0o755); err != nil {
+		log.Fatal(err)
+	}
+
+	level := newCompound().str("LevelName", "Bedrock Test").int("StorageVersion", 10).long("RandomSeed", 42).bytes()
+	header := binary.LittleEndian.AppendUint32(nil, 10)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(level)))
+
+	if err := os.WriteFile(filepath.Join(world, "level.dat"), append(header, level...), 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	cow := []byte{1, 0, 0, 0, 0xff, 0xff, 0xff, 0xff}
+	zombie := []byte{2, 0, 0, 0, 0xff, 0xff, 0xff, 0xff}
+
+	// the chunks go into a table with zlib blocks, as written by older versions of the game
+	db := openDB(opt.ZlibCompression)
+
+	b := &leveldb.Batch{}
+
+	b.Put(chunkKey(0, 0, 0, 0x2c), []byte{40})
+	b.Put(chunkKey(0, 0, 0, 0x2f, 0xfc), []byte{9, 0})
+	b.Put(chunkKey(0, 0, 0, 0x31), append(
+		newCompound().str("id", "Chest").int("x", 1).int("y", 64).int("z", 2).bytes(),
+		newCompound().str("id", "Sign").int("x", 3).int("y", 65).int("z", 4).bytes()...,
+	))
+	b.Put(chunkKey(-1, 2, 1, 0x2c), []byte{22})
+	b.Put(chunkKey(-1, 2, 1, 0x32), newCompound().str("identifier", "minecraft:ghast").floats("Pos", -10.5, 70, 40.5).bytes())
+
+	if err := db.Write(b, nil); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := db.CompactRange(util.Range{}); err != nil {
+		log.Fatal(err)
+	}
+
+	closeDB(db)
+
+	// the other records stay in the log until the next open flushes them into a table with zlib-raw blocks, the
+	// compression of current versions
+	db = openDB(opt.ZlibRawCompression)
+
+	b = &leveldb.Batch{}
+
+	b.Put([]byte("BiomeData"), []byte{0})
+	b.Put([]byte("~local_player"), newCompound().int("PlayerLevel", 1).floats("Pos", 0, 0, 0).bytes())
+	b.Put(append([]byte("digp"), chunkKey(0, 0, 0, 0)[:8]...), append(append([]byte{}, cow...), zombie...))
+	b.Put(append([]byte("actorprefix"), cow...), newCompound().str("identifier", "minecraft:cow").bytes())
+	b.Put(append([]byte("actorprefix"), zombie...), newCompound().str("identifier", "minecraft:zombie").bytes())
+	b.Put([]byte("player_server_8f1b3c6e-2a4d-4c7e-9b0a-1d2e3f405162"), newCompound().int("PlayerLevel", 7).bytes())
+
+	if err := db.Write(b, nil); err != nil {
+		log.Fatal(err)
+	}
+
+	closeDB(db)
+
+	// the following writes stay in the log
+	db = openDB(opt.ZlibRawCompression)
+
+	if err := db.Put([]byte("~local_player"), newCompound().int("PlayerLevel", 30).floats("Pos", 1.5, 64, 2.5).bytes(), nil); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := db.Put(chunkKey(5, -3, 0, 0x2c), []byte{40}, nil); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := db.Delete(chunkKey(0, 0, 0, 0x2f, 0xfc), nil); err != nil {
+		log.Fatal(err)
+	}
+
+	closeDB(db)
+}
@@ -0,0 +1,95 @@
+package volume
+
+import (
+	"github.com/nitwhiz/go-nbt/block"
+	"github.com/nitwhiz/go-nbt/nbt"
+)
+
+// Volume is a box of blocks together with their block entities and the entities inside, the common representation
+// of structure and schematic files. Positions are relative to the corner with the smallest coordinates.
+type Volume struct {
+	Size    [3]int
+	Palette []block.State
+	// Indices holds a palette index per block, ordered by x first, then z, then y.
+	Indices []int
+	// BlockEntities holds the block entity data by position. Compounds contain the id but not the position.
+	BlockEntities map[[3]int]nbt.Compound
+	Entities      []Entity
+	lookup        map[string]int
+	// indexed is the number of palette entries in lookup
+	indexed int
+}
+
+// Entity is an entity inside a volume. NBT holds the complete entity data including its id.
+type Entity struct {
+	Pos [3]float64
+	NBT nbt.Compound
+}
+
+// New returns a volume of the given size filled with air.
+func New(sizeX, sizeY, sizeZ int) *Volume {
+	return &Volume{
+		Size:          [3]int{sizeX, sizeY, sizeZ},
+		Palette:       []block.State{block.Air},
+		Indices:       make([]int, sizeX*sizeY*sizeZ),
+		BlockEntities: map[[3]int]nbt.Compound{},
+	}
+}
+
+// Index returns the position of x, y, z in Indices.
+func (v *Volume) Index(x, y, z int) int {
+	return (y*v.Size[2]+z)*v.Size[0] + x
+}
+
+// Pos returns the position of the block at i in Indices.
+func (v *Volume) Pos(i int) (x, y, z int) {
+	return i % v.Size[0], i / (v.Size[0] * v.Size[2]), i / v.Size[0] % v.Size[2]
+}
+
+// Contains reports whether x, y, z is inside the volume.
+func (v *Volume) Contains(x, y, z int) bool {
+	return x >= 0 && y >= 0 && z >= 0 && x < v.Size[0] && y < v.Size[1] && z < v.Size[2]
+}
+
+// At returns the block state at x, y, z.
+func (v *Volume) At(x, y, z int) block.State {
+	return v.Palette[v.Indices[v.Index(x, y, z)]]
+}
+
+// Set replaces the block state at x, y, z, adding s to the palette if needed.
+func (v *Volume) Set(x, y, z int, s block.State) {
+	v.Indices[v.Index(x, y, z)] = v.StateIndex(s)
+}
+
+// StateIndex returns the palette index of s, adding s to the palette if it's not there yet. Palette entries
+// replaced directly instead of through MapStates may be missed.
+func (v *Volume) StateIndex(s block.State) int {
+	if v.lookup == nil || v.indexed > len(v.Palette) {
+		v.lookup, v.indexed = make(map[string]int, len(v.Palette)), 0
+	}
+
+	for ; v.indexed < len(v.Palette); v.indexed++ {
+		key := v.Palette[v.indexed].String()
+
+		if _, ok := v.lookup[key]; !ok {
+			v.lookup[key] = v.indexed
+		}
+	}
+
+	if i, ok := v.lookup[s.String()]; ok {
+		return i
+	}
+
+	v.Palette = append(v.Palette, s)
+
+	return len(v.Palette) - 1
+}
+
+// MapStates replaces every palette entry with the result of f, e.g. to translate between editions or versions.
+func (v *Volume) MapStates(f func(block.State) block.State) {
+	for i, s := range v.Palette {
+		v.Palette[i] = f(s)
+	}
+
+	v.lookup = nil
+}
@@ -0,0 +1,49 @@
+package volume
+
+import (
+	"testing"
+
+	"github.com/nitwhiz/go-nbt/block"
+)
+
+func TestVolume(t *testing.T) {
+	v := New(3, 4, 5)
+
+	if len(v.Indices) != 60 {
+		t.Fatalf("expected 60 blocks, got %d", len(v.Indices))
+	}
+
+	stone := block.State{Name: "minecraft:stone"}
+
+	v.Set(2, 3, 4, stone)
+	v.Set(0, 0, 1, stone)
+
+	if len(v.Palette) != 2 {
+		t.Fatalf("expected air and stone in the palette, got %v", v.Palette)
+	}
+
+	if !v.At(2, 3, 4).Equal(stone) || !v.At(1, 1, 1).Equal(block.Air) {
+		t.Fatalf("unexpected blocks %s and %s", v.At(2, 3, 4), v.At(1, 1, 1))
+	}
+
+	if x, y, z := v.Pos(v.Index(2, 3, 4)); x != 2 || y != 3 || z != 4 {
+		t.Fatalf("expected 2,3,4, got %d,%d,%d", x, y, z)
+	}
+
+	if v.Contains(3, 0, 0) || !v.Contains(2, 3, 4) {
+		t.Fatalf("unexpected bounds")
+	}
+
+	v.MapStates(func(s block.State) block.State {
+		if s.Equal(stone) {
+			return block.Air
+		}
+
+		return s
+	})
+
+	// both palette entries are air now, the first one is used
+	if i := v.StateIndex(block.Air); i != 0 {
+		t.Fatalf("expected index 0 for air, got %d", i)
+	}
+}
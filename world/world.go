@@ -0,0 +1,361 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nitwhiz/go-nbt/mc"
+	"github.com/nitwhiz/go-nbt/nbt"
+	"github.com/nitwhiz/go-nbt/region"
+)
+
+const (
+	Overworld = "minecraft:overworld"
+	Nether    = "minecraft:the_nether"
+	End       = "minecraft:the_end"
+)
+
+// Storage is one of the region directories of a dimension.
+type Storage string
+
+const (
+	StorageChunks   Storage = "region"
+	StorageEntities Storage = "entities"
+	StoragePOI      Storage = "poi"
+)
+
+// World is a Java Edition save directory.
+type World struct {
+	dir string
+}
+
+// Level holds commonly used fields of the Data compound in level.dat. Tag is the complete Data compound.
+type Level struct {
+	LevelName   string `nbt:"LevelName"`
+	DataVersion int32  `nbt:"DataVersion"`
+	Version     struct {
+		ID       int32  `nbt:"Id"`
+		Name     string `nbt:"Name"`
+		Series   string `nbt:"Series"`
+		Snapshot int8   `nbt:"Snapshot"`
+	} `nbt:"Version"`
+	GameType   int32 `nbt:"GameType"`
+	Difficulty int8  `nbt:"Difficulty"`
+	Hardcore   int8  `nbt:"hardcore"`
+	SpawnX     int32 `nbt:"SpawnX"`
+	SpawnY     int32 `nbt:"SpawnY"`
+	SpawnZ     int32 `nbt:"SpawnZ"`
+	Time       int64 `nbt:"Time"`
+	DayTime    int64 `nbt:"DayTime"`
+	LastPlayed int64 `nbt:"LastPlayed"`
+	Tag        *nbt.Tag
+}
+
+// Dimension is a dimension of a World with its region directories.
+type Dimension struct {
+	ID  string
+	dir string
+}
+
+// Chunk is a chunk read while iterating a dimension. X and Z are world chunk coordinates.
+type Chunk struct {
+	X   int
+	Z   int
+	Tag *nbt.Tag
+}
+
+// Open opens the save directory dir, which has to contain a level.dat.
+func Open(dir string) (w *World, err error) {
+	if _, err = os.Stat(filepath.Join(dir, "level.dat")); err != nil {
+		return nil, fmt.Errorf("world: %s is not a world: %w", dir, err)
+	}
+
+	return &World{dir: dir}, nil
+}
+
+// Dir returns the save directory.
+func (w *World) Dir() string {
+	return w.dir
+}
+
+func readFile(path string) (tag *nbt.Tag, err error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return
+	}
+
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	tag = &nbt.Tag{}
+
+	if err = nbt.NewDecoder(f).Decode(tag); err != nil {
+		return nil, fmt.Errorf("world: decoding %s: %w", path, err)
+	}
+
+	return
+}
+
+// LevelTag returns the root tag of level.dat.
+func (w *World) LevelTag() (*nbt.Tag, error) {
+	return readFile(filepath.Join(w.dir, "level.dat"))
+}
+
+// Level returns the level metadata from level.dat.
+func (w *World) Level() (l *Level, err error) {
+	root, err := w.LevelTag()
+
+	if err != nil {
+		return
+	}
+
+	data, ok := root.Find("Data")
+
+	if !ok {
+		return nil, errors.New("world: level.dat has no Data compound")
+	}
+
+	l = &Level{Tag: data}
+
+	if err = nbt.UnmarshalTag(l, data); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// LevelData returns the Data compound of level.dat as mc.Level. Entries not covered by mc.Level are kept in its
+// Rest compound.
+func (w *World) LevelData() (l *mc.Level, err error) {
+	root, err := w.LevelTag()
+
+	if err != nil {
+		return
+	}
+
+	if _, ok := root.Find("Data"); !ok {
+		return nil, errors.New("world: level.dat has no Data compound")
+	}
+
+	ld := &mc.LevelDat{}
+
+	if err = nbt.UnmarshalTag(ld, root); err != nil {
+		return
+	}
+
+	return &ld.Data, nil
+}
+
+// Dimension returns the dimension with the given id, e.g. Overworld or "mymod:mining".
+func (w *World) Dimension(id string) *Dimension {
+	var dir string
+
+	switch id {
+	case Overworld:
+		dir = w.dir
+	case Nether:
+		dir = filepath.Join(w.dir, "DIM-1")
+	case End:
+		dir = filepath.Join(w.dir, "DIM1")
+	default:
+		namespace, path, found := strings.Cut(id, ":")
+
+		if !found {
+			namespace, path = "minecraft", id
+		}
+
+		dir = filepath.Join(w.dir, "dimensions", namespace, filepath.FromSlash(path))
+	}
+
+	return &Dimension{ID: id, dir: dir}
+}
+
+// Dimensions returns all dimensions with a region directory, vanilla ones first.
+func (w *World) Dimensions() (res []*Dimension, err error) {
+	for _, id := range []string{Overworld, Nether, End} {
+		if d := w.Dimension(id); d.exists() {
+			res = append(res, d)
+		}
+	}
+
+	root := filepath.Join(w.dir, "dimensions")
+
+	err = filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && path == root {
+				return filepath.SkipDir
+			}
+
+			return err
+		}
+
+		if !entry.IsDir() || entry.Name() != string(StorageChunks) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, filepath.Dir(path))
+
+		if err != nil {
+			return err
+		}
+
+		namespace, dimPath, _ := strings.Cut(filepath.ToSlash(rel), "/")
+		id := namespace + ":" + dimPath
+
+		if !slices.ContainsFunc(res, func(d *Dimension) bool { return d.ID == id }) {
+			res = append(res, w.Dimension(id))
+		}
+
+		return filepath.SkipDir
+	})
+
+	return
+}
+
+func (d *Dimension) exists() bool {
+	info, err := os.Stat(filepath.Join(d.dir, string(StorageChunks)))
+
+	return err == nil && info.IsDir()
+}
+
+// Dir returns the directory containing the region directories of the dimension.
+func (d *Dimension) Dir() string {
+	return d.dir
+}
+
+// RegionFiles returns the paths of all region files in storage s.
+func (d *Dimension) RegionFiles(s Storage) (paths []string, err error) {
+	paths, err = filepath.Glob(filepath.Join(d.dir, string(s), "r.*.*.mca"))
+
+	slices.Sort(paths)
+
+	return
+}
+
+// OpenRegion opens the region file at regionX, regionZ in storage s for reading.
+func (d *Dimension) OpenRegion(s Storage, regionX, regionZ int) (*region.File, error) {
+	return region.Open(filepath.Join(d.dir, string(s), region.FileName(regionX, regionZ)))
+}
+
+// ReadChunk reads the chunk at the world chunk coordinates chunkX, chunkZ from storage s.
+func (d *Dimension) ReadChunk(s Storage, chunkX, chunkZ int) (tag *nbt.Tag, err error) {
+	regionX, regionZ := region.RegionOf(chunkX, chunkZ)
+
+	r, err := d.OpenRegion(s, regionX, regionZ)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, region.ErrChunkNotFound
+	} else if err != nil {
+		return
+	}
+
+	defer func(r *region.File) {
+		_ = r.Close()
+	}(r)
+
+	return r.ReadChunk(chunkX, chunkZ)
+}
+
+// Chunks iterates over all chunks in storage s, region file by region file. Iteration continues after errors
+// reading single chunks, stop it by breaking out of the loop.
+func (d *Dimension) Chunks(s Storage) iter.Seq2[*Chunk, error] {
+	return func(yield func(*Chunk, error) bool) {
+		paths, err := d.RegionFiles(s)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for _, path := range paths {
+			if !yieldRegionChunks(path, yield) {
+				return
+			}
+		}
+	}
+}
+
+func yieldRegionChunks(path string, yield func(*Chunk, error) bool) bool {
+	r, err := region.Open(path)
+
+	if err != nil {
+		return yield(nil, err)
+	}
+
+	defer func(r *region.File) {
+		_ = r.Close()
+	}(r)
+
+	regionX, regionZ, _ := r.Position()
+
+	for _, pos := range r.Chunks() {
+		c := &Chunk{
+			X: regionX*32 + pos.X,
+			Z: regionZ*32 + pos.Z,
+		}
+
+		c.Tag, err = r.ReadChunk(pos.X, pos.Z)
+
+		if err != nil {
+			c = nil
+		}
+
+		if !yield(c, err) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Players returns the UUIDs of all players with a file in playerdata.
+func (w *World) Players() (uuids []string, err error) {
+	paths, err := filepath.Glob(filepath.Join(w.dir, "playerdata", "*.dat"))
+
+	if err != nil {
+		return
+	}
+
+	for _, path := range paths {
+		uuids = append(uuids, strings.TrimSuffix(filepath.Base(path), ".dat"))
+	}
+
+	slices.Sort(uuids)
+
+	return
+}
+
+// PlayerTag returns the root tag of the player file of uuid.
+func (w *World) PlayerTag(uuid string) (*nbt.Tag, error) {
+	return readFile(filepath.Join(w.dir, "playerdata", uuid+".dat"))
+}
+
+// Player returns the player file of uuid.
+func (w *World) Player(uuid string) (p *mc.Player, err error) {
+	p = &mc.Player{}
+
+	if err = w.UnmarshalPlayer(uuid, p); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// UnmarshalPlayer decodes the player file of uuid into v using nbt.UnmarshalTag. The fields of v refer to the
+// entries of the file's root compound.
+func (w *World) UnmarshalPlayer(uuid string, v any) (err error) {
+	tag, err := w.PlayerTag(uuid)
+
+	if err != nil {
+		return
+	}
+
+	return nbt.UnmarshalTag(v, tag)
+}
@@ -0,0 +1,264 @@
+package world
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nitwhiz/go-nbt/nbt"
+	"github.com/nitwhiz/go-nbt/region"
+)
+
+const playerUUID = "0f4c3a2e-6b1d-4e8a-9c7f-2d5b8e1a3c90"
+
+func chunkTag(x, z int32) *nbt.Tag {
+	return &nbt.Tag{
+		Type: nbt.TypeCompound,
+		Name: []byte{},
+		Value: nbt.Compound{
+			"xPos": {Type: nbt.TypeInt, Name: []byte("xPos"), Value: x},
+			"zPos": {Type: nbt.TypeInt, Name: []byte("zPos"), Value: z},
+		},
+	}
+}
+
+func writeRegion(t *testing.T, dir string, chunks ...[2]int) {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	regionX, regionZ := region.RegionOf(chunks[0][0], chunks[0][1])
+
+	r, err := region.OpenWrite(filepath.Join(dir, region.FileName(regionX, regionZ)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(r *region.File) {
+		_ = r.Close()
+	}(r)
+
+	for _, c := range chunks {
+		if err := r.WriteChunk(c[0], c[1], chunkTag(int32(c[0]), int32(c[1])), region.CompressionZlib); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// testWorld builds a small world with chunks in the overworld, the nether and a custom dimension.
+func testWorld(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	level, err := os.ReadFile("../testdata/level.dat")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "level.dat"), level, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	writeRegion(t, filepath.Join(dir, "region"), [2]int{0, 0}, [2]int{1, 0})
+	writeRegion(t, filepath.Join(dir, "region"), [2]int{-1, -1})
+	writeRegion(t, filepath.Join(dir, "entities"), [2]int{0, 0})
+	writeRegion(t, filepath.Join(dir, "DIM-1", "region"), [2]int{3, 4})
+	writeRegion(t, filepath.Join(dir, "dimensions", "mymod", "deep", "mines", "region"), [2]int{7, 7})
+
+	type playerData struct {
+		Health  float32 `nbt:"Health"`
+		XpLevel int32   `nbt:"XpLevel"`
+	}
+
+	player, err := nbt.Marshal(&struct {
+		Root playerData `nbt:""`
+	}{Root: playerData{Health: 20, XpLevel: 3}}, nbt.WithCompression(nbt.CompressionGzip))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "playerdata"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "playerdata", playerUUID+".dat"), player, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestLevel(t *testing.T) {
+	w, err := Open(testWorld(t))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := w.Level()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l.LevelName != "test-01" {
+		t.Fatalf("expected level name test-01, got %s", l.LevelName)
+	}
+
+	if l.DataVersion != 4189 {
+		t.Fatalf("expected data version 4189, got %d", l.DataVersion)
+	}
+
+	if l.Tag == nil || l.Tag.Type != nbt.TypeCompound {
+		t.Fatalf("expected Data compound, got %v", l.Tag)
+	}
+
+	ld, err := w.LevelData()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ld.LevelName != "test-01" {
+		t.Fatalf("expected level name test-01, got %s", ld.LevelName)
+	}
+
+	if _, ok := ld.Rest["GameRules"]; ok {
+		t.Fatalf("expected GameRules to be mapped to a field")
+	}
+
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Fatalf("expected error opening a directory without level.dat")
+	}
+}
+
+func TestDimensions(t *testing.T) {
+	w, err := Open(testWorld(t))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dims, err := w.Dimensions()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{Overworld, Nether, "mymod:deep/mines"}
+
+	if len(dims) != len(expected) {
+		t.Fatalf("expected %d dimensions, got %d", len(expected), len(dims))
+	}
+
+	for i, id := range expected {
+		if dims[i].ID != id {
+			t.Fatalf("expected dimension %s, got %s", id, dims[i].ID)
+		}
+	}
+
+	tag, err := w.Dimension(Nether).ReadChunk(StorageChunks, 3, 4)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if zPos, _ := tag.Find("zPos"); zPos.Value != int32(4) {
+		t.Fatalf("expected zPos 4, got %v", zPos.Value)
+	}
+
+	if _, err := w.Dimension(End).ReadChunk(StorageChunks, 0, 0); !errors.Is(err, region.ErrChunkNotFound) {
+		t.Fatalf("expected ErrChunkNotFound, got %v", err)
+	}
+}
+
+func TestChunks(t *testing.T) {
+	w, err := Open(testWorld(t))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[[2]int]bool{}
+
+	for c, err := range w.Dimension(Overworld).Chunks(StorageChunks) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		xPos, _ := c.Tag.Find("xPos")
+		zPos, _ := c.Tag.Find("zPos")
+
+		if xPos.Value != int32(c.X) || zPos.Value != int32(c.Z) {
+			t.Fatalf("chunk %d,%d has position %v,%v", c.X, c.Z, xPos.Value, zPos.Value)
+		}
+
+		seen[[2]int{c.X, c.Z}] = true
+	}
+
+	if len(seen) != 3 || !seen[[2]int{-1, -1}] {
+		t.Fatalf("expected 3 chunks including -1,-1, got %v", seen)
+	}
+
+	count := 0
+
+	for _, err := range w.Dimension(Overworld).Chunks(StorageEntities) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		count++
+	}
+
+	if count != 1 {
+		t.Fatalf("expected 1 entity chunk, got %d", count)
+	}
+}
+
+func TestPlayers(t *testing.T) {
+	w, err := Open(testWorld(t))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uuids, err := w.Players()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(uuids) != 1 || uuids[0] != playerUUID {
+		t.Fatalf("expected player %s, got %v", playerUUID, uuids)
+	}
+
+	var player struct {
+		Health  float32 `nbt:"Health"`
+		XpLevel int32   `nbt:"XpLevel"`
+	}
+
+	if err := w.UnmarshalPlayer(playerUUID, &player); err != nil {
+		t.Fatal(err)
+	}
+
+	if player.Health != 20 || player.XpLevel != 3 {
+		t.Fatalf("expected health 20 and level 3, got %v and %d", player.Health, player.XpLevel)
+	}
+
+	p, err := w.Player(playerUUID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Health != 20 || p.XpLevel != 3 {
+		t.Fatalf("expected health 20 and level 3, got %v and %d", p.Health, p.XpLevel)
+	}
+}